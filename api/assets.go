@@ -1,10 +1,11 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 
@@ -12,28 +13,34 @@ import (
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
 	"github.com/segment3d-app/segment3d-be/util"
 )
 
 type AssetResponse struct {
-	ID                   string       `json:"id"`
-	Title                string       `json:"title"`
-	Slug                 string       `json:"slug"`
-	Type                 string       `json:"type"`
-	ThumbnailUrl         string       `json:"thumbnailUrl"`
-	PhotoDirUrl          string       `json:"photoDirUrl"`
-	SplatUrl             string       `json:"splatUrl"`
-	PCLUrl               string       `json:"pclUrl"`
-	PCLColmapUrl         string       `json:"pclColmapUrl"`
-	SegmentedPclDirUrl   string       `json:"segmentedPclDirUrl"`
-	SegmentedSplatDirUrl string       `json:"segmentedSplatDirUrl"`
-	IsPrivate            bool         `json:"isPrivate"`
-	Status               string       `json:"status"`
-	Likes                int64        `json:"likes"`
-	CreatedAt            string       `json:"createdAt"`
-	UpdatedAt            string       `json:"updatedAt"`
-	User                 UserResponse `json:"user"`
-	IsLikedByMe          bool         `json:"isLikedByMe"`
+	ID                   string `json:"id"`
+	Title                string `json:"title"`
+	Slug                 string `json:"slug"`
+	Type                 string `json:"type"`
+	ThumbnailUrl         string `json:"thumbnailUrl"`
+	PhotoDirUrl          string `json:"photoDirUrl"`
+	SplatUrl             string `json:"splatUrl"`
+	PCLUrl               string `json:"pclUrl"`
+	PCLColmapUrl         string `json:"pclColmapUrl"`
+	SegmentedPclDirUrl   string `json:"segmentedPclDirUrl"`
+	SegmentedSplatDirUrl string `json:"segmentedSplatDirUrl"`
+	IsPrivate            bool   `json:"isPrivate"`
+	Status               string `json:"status"`
+	FailureStage         string `json:"failureStage"`
+	FailureReason        string `json:"failureReason"`
+	FailureLogsUrl       string `json:"failureLogsUrl"`
+	// Progress is the latest progress reported for the running stage.
+	Progress    *AssetProgressResponse `json:"progress"`
+	Likes       int64                  `json:"likes"`
+	CreatedAt   string                 `json:"createdAt"`
+	UpdatedAt   string                 `json:"updatedAt"`
+	User        UserResponse           `json:"user"`
+	IsLikedByMe bool                   `json:"isLikedByMe"`
 }
 
 type ReturnAssetResponseArg struct {
@@ -58,6 +65,10 @@ func ReturnAssetResponse(arg ReturnAssetResponseArg) AssetResponse {
 		IsPrivate:            arg.Asset.IsPrivate,
 		Likes:                int64(arg.Asset.Likes),
 		Status:               arg.Asset.Status,
+		FailureStage:         arg.Asset.FailureStage.String,
+		FailureReason:        arg.Asset.FailureReason.String,
+		FailureLogsUrl:       arg.Asset.FailureLogsUrl.String,
+		Progress:             ReturnAssetProgressResponse(arg.Asset),
 		CreatedAt:            arg.Asset.CreatedAt.String(),
 		UpdatedAt:            arg.Asset.UpdatedAt.String(),
 		User:                 *ReturnUserResponse(arg.User),
@@ -70,7 +81,7 @@ type CreateAssetRequest struct {
 	IsPrivate   *bool    `json:"isPrivate" binding:"required"`
 	PhotoDirUrl string   `json:"photoDirUrl" binding:"required"`
 	PCLUrl      string   `json:"pclUrl"`
-	Type        string   `json:"type" binding:"required,oneof=lidar non_lidar"`
+	Type        string   `json:"type" binding:"required"`
 	Tags        []string `json:"tags"`
 }
 
@@ -79,16 +90,12 @@ type CreateAssetsResponse struct {
 	Message string        `json:"message"`
 }
 
-type getThumbnailResponse struct {
-	Message string `json:"message"`
-	Url     string `json:"url"`
-}
-
 type GenerateColmapEvent struct {
 	AssetID       string `json:"asset_id"`
 	PhotoDirUrl   string `json:"photo_dir_url"`
 	Type          string `json:"type"`
 	PointCloudUrl string `json:"point_cloud_url"`
+	JobID         string `json:"job_id"`
 }
 
 // createAsset creates a new asset with provided details
@@ -96,12 +103,17 @@ type GenerateColmapEvent struct {
 // @Description Creates a new asset based on the title, privacy setting, asset URL, and asset type provided in the request.
 //
 //	It also attempts to retrieve a thumbnail for the asset from the specified asset URL.
+//	The asset type selects the processing pipeline: lidar, non_lidar or a type from the pipeline definitions.
+//	photoDirUrl must be a directory with jpg or png photos and pclUrl a ply file, both under /files/<uid>/ of the caller,
+//	given as storage paths or as urls on the storage server.
 //
 // @Tags assets
 // @Accept json
 // @Produce json
 // @Param CreateAssetRequest body CreateAssetRequest true "Create Asset Request"
 // @Success 202 {object} CreateAssetsResponse "Asset creation successful, returns created asset details along with a success message."
+// @Failure 400 {object} ErrorResponse "Error: Unknown asset type or missing point cloud"
+// @Failure 422 {object} ErrorResponse "Error: Photo directory or point cloud is not a valid file of the caller"
 // @Security BearerAuth
 // @Router /assets [post]
 func (server *Server) createAsset(ctx *gin.Context) {
@@ -128,172 +140,144 @@ func (server *Server) createAsset(ctx *gin.Context) {
 		return
 	}
 
-	slug := util.GenerateBaseSlug(req.Title)
-	pattern := slug + "%"
-	existingSlugs, err := server.store.GetSlug(ctx, pattern)
+	err = server.checkAssetType(req.Type, len(req.PCLUrl) > 0)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	if len(existingSlugs) > 0 {
-		slug = slug + fmt.Sprintf("-%d", (len(existingSlugs)+1))
-	}
 
-	urlLink := strings.Replace(req.PhotoDirUrl, "files", "thumbnail", -1)
-
-	resp, err := http.Get(fmt.Sprintf("%s%s", server.config.StorageUrl, urlLink))
+	// workers fetch whatever the asset points at, so only the caller's own
+	// files are accepted
+	photoDirUrl, err := server.checkPhotoDir(ctx, user.Uid, req.PhotoDirUrl)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	defer resp.Body.Close()
-
-	contentType := resp.Header.Get("Content-Type")
-	if !strings.HasPrefix(contentType, "application/json") {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("expected JSON response, got: %s", contentType)))
+		ctx.JSON(assetPathErrorStatus(err), errorResponse(err))
 		return
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	var response getThumbnailResponse
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+	pclUrl := ""
+	if len(req.PCLUrl) > 0 {
+		pclUrl, err = server.checkPointCloud(ctx, user.Uid, req.PCLUrl)
+		if err != nil {
+			ctx.JSON(assetPathErrorStatus(err), errorResponse(err))
+			return
+		}
 	}
 
-	arg := db.CreateAssetParams{
-		Uid:          user.Uid,
-		Title:        req.Title,
-		Slug:         slug,
-		Status:       "created",
-		PhotoDirUrl:  req.PhotoDirUrl,
-		Type:         req.Type,
-		ThumbnailUrl: response.Url,
-		IsPrivate:    false,
-		Likes:        0,
+	arg := newAssetParams{
+		Title:       req.Title,
+		Type:        req.Type,
+		Tags:        req.Tags,
+		PhotoDirUrl: photoDirUrl,
+		PclUrl:      pclUrl,
 	}
-
 	if req.IsPrivate != nil {
 		arg.IsPrivate = *req.IsPrivate
 	}
 
-	asset, err := server.store.CreateAsset(ctx, arg)
+	asset, err := server.startAsset(ctx, user, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	if len(req.PCLUrl) > 0 {
-		argPCL := db.UpdatePointCloudUrlFromLidarParams{
-			Uid:    user.Uid,
-			ID:     asset.ID,
-			PclUrl: sql.NullString{String: req.PCLUrl, Valid: true},
-		}
-
-		asset, err = server.store.UpdatePointCloudUrlFromLidar(ctx, argPCL)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-	}
 
-	tags, err := server.store.GetTagsByTagsName(ctx, req.Tags)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+	res := CreateAssetsResponse{
+		Message: "generate splat from model",
+		Asset:   ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
 	}
 
-	var allTags []db.Tags
-	allTags = append(allTags, tags...)
-
-	for _, curTag := range req.Tags {
-		createTag := true
-		for _, tag := range tags {
-			if curTag == tag.Name {
-				createTag = false
-				break
-			}
-		}
+	ctx.JSON(http.StatusAccepted, res)
+}
 
-		if createTag {
-			tag, err := server.store.CreateTag(ctx, db.CreateTagParams{
-				Name: curTag,
-				Slug: util.GenerateBaseSlug(curTag),
-			})
-			if err != nil {
-				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-				return
-			}
+// checkAssetType makes sure assets of the type can be created, given whether
+// a point cloud was uploaded with them.
+func (server *Server) checkAssetType(assetType string, hasPcl bool) error {
+	if !server.pipelines.Has(assetType) {
+		return fmt.Errorf("unknown asset type %q", assetType)
+	}
 
-			allTags = append(allTags, tag)
-		}
+	// pipelines that skip colmap start from the uploaded point cloud
+	first := server.pipelines.For(assetType).First()
+	if first.Stage != pipeline.StageColmap && !hasPcl {
+		return fmt.Errorf("assets of type %s start at %s and require a pclUrl", assetType, first.Stage)
 	}
 
-	for _, tag := range allTags {
-		_, err := server.store.CreateAssetsToTags(ctx, db.CreateAssetsToTagsParams{
-			AssetsId: asset.ID,
-			TagsId:   tag.ID,
-		})
+	return nil
+}
 
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-	}
+// newAssetParams is what an asset is created from, by createAsset or by
+// finalizing an upload session.
+type newAssetParams struct {
+	Title       string
+	Type        string
+	IsPrivate   bool
+	Tags        []string
+	PhotoDirUrl string
+	PclUrl      string
+}
 
-	asset, err = publishGenerateColmapEvent(server, ctx, &asset, &user)
+// assetSlug picks the slug of a new asset from its title.
+func (server *Server) assetSlug(ctx context.Context, title string) (string, error) {
+	slug := util.GenerateBaseSlug(title)
+	pattern := slug + "%"
+	existingSlugs, err := server.store.GetSlug(ctx, pattern)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return "", err
 	}
-
-	res := CreateAssetsResponse{
-		Message: "generate splat from model",
-		Asset:   ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
+	if len(existingSlugs) > 0 {
+		slug = slug + fmt.Sprintf("-%d", (len(existingSlugs)+1))
 	}
 
-	ctx.JSON(http.StatusAccepted, res)
+	return slug, nil
 }
 
-func publishGenerateColmapEvent(server *Server, ginCtx *gin.Context, asset *db.Assets, user *db.Users) (db.Assets, error) {
-	// generate message
-	msg, err := json.Marshal(GenerateColmapEvent{
-		AssetID:       asset.ID.String(),
-		PhotoDirUrl:   asset.PhotoDirUrl,
-		Type:          asset.Type,
-		PointCloudUrl: asset.PclUrl.String,
-	})
+// startAsset creates the asset and queues the first stage of its pipeline.
+func (server *Server) startAsset(ctx context.Context, user db.Users, arg newAssetParams) (db.Assets, error) {
+	first := server.pipelines.For(arg.Type).First()
+
+	slug, err := server.assetSlug(ctx, arg.Title)
 	if err != nil {
-		return *asset, err
+		return db.Assets{}, err
 	}
 
-	err = server.rabbitmq.PublishEvent("process", msg)
+	thumbnailUrl, err := server.storage.Thumbnail(ctx, arg.PhotoDirUrl)
 	if err != nil {
-		return *asset, err
+		return db.Assets{}, err
+	}
+
+	jobID := uuid.New()
+	txArg := db.CreateAssetTxParams{
+		CreateAssetParams: db.CreateAssetParams{
+			Uid:          user.Uid,
+			Title:        arg.Title,
+			Slug:         slug,
+			Status:       string(pipeline.StateCreated),
+			PhotoDirUrl:  arg.PhotoDirUrl,
+			Type:         arg.Type,
+			ThumbnailUrl: thumbnailUrl,
+			IsPrivate:    arg.IsPrivate,
+			Likes:        0,
+		},
+		Tags: arg.Tags,
+		Event: func(asset db.Assets) (db.CreateOutboxEventParams, error) {
+			return stageEvent(&asset, first, jobID)
+		},
+		Status: string(first.Stage.State()),
+		Stage:  string(first.Stage),
+		JobID:  jobID,
+		Notify: transitionWebhooks(pipeline.StateCreated, first.Stage.State()),
 	}
 
-	if asset.Status == "created" {
-		arg := db.UpdateAssetStatusParams{
-			Uid:    user.Uid,
-			ID:     asset.ID,
-			Status: "generating sparse point cloud",
-		}
-
-		newAsset, err := server.store.UpdateAssetStatus(ginCtx, arg)
-		if err != nil {
-			return *asset, err
-		}
-
-		return newAsset, nil
+	if len(arg.PclUrl) > 0 {
+		txArg.PclUrl = sql.NullString{String: arg.PclUrl, Valid: true}
 	}
 
-	return *asset, nil
+	// the processing event is queued in the outbox in the same transaction
+	// and published by the outbox relay, so a broker outage only delays it
+	result, err := server.store.CreateAssetTx(ctx, txArg)
+	if err != nil {
+		return db.Assets{}, err
+	}
+	return result.Asset, nil
 }
 
 type getAllAssetsQuery struct {
@@ -377,6 +361,15 @@ func (server *Server) getAllAssets(ctx *gin.Context) {
 				IsPrivate:            asset.IsPrivate,
 				Likes:                asset.Likes,
 				Status:               asset.Status,
+				FailureStage:         asset.FailureStage,
+				FailureReason:        asset.FailureReason,
+				FailureLogsUrl:       asset.FailureLogsUrl,
+				ProgressStage:        asset.ProgressStage,
+				ProgressPercent:      asset.ProgressPercent,
+				ProgressIteration:    asset.ProgressIteration,
+				ProgressEtaSeconds:   asset.ProgressEtaSeconds,
+				ProgressPreviewUrl:   asset.ProgressPreviewUrl,
+				ProgressUpdatedAt:    asset.ProgressUpdatedAt,
 				CreatedAt:            asset.CreatedAt,
 				UpdatedAt:            asset.UpdatedAt,
 			}
@@ -440,6 +433,15 @@ func (server *Server) getAllAssets(ctx *gin.Context) {
 				IsPrivate:            asset.IsPrivate,
 				Likes:                asset.Likes,
 				Status:               asset.Status,
+				FailureStage:         asset.FailureStage,
+				FailureReason:        asset.FailureReason,
+				FailureLogsUrl:       asset.FailureLogsUrl,
+				ProgressStage:        asset.ProgressStage,
+				ProgressPercent:      asset.ProgressPercent,
+				ProgressIteration:    asset.ProgressIteration,
+				ProgressEtaSeconds:   asset.ProgressEtaSeconds,
+				ProgressPreviewUrl:   asset.ProgressPreviewUrl,
+				ProgressUpdatedAt:    asset.ProgressUpdatedAt,
 				CreatedAt:            asset.CreatedAt,
 				UpdatedAt:            asset.UpdatedAt,
 			}
@@ -556,6 +558,15 @@ func (server *Server) getMyAssets(ctx *gin.Context) {
 			IsPrivate:            asset.IsPrivate,
 			Likes:                asset.Likes,
 			Status:               asset.Status,
+			FailureStage:         asset.FailureStage,
+			FailureReason:        asset.FailureReason,
+			FailureLogsUrl:       asset.FailureLogsUrl,
+			ProgressStage:        asset.ProgressStage,
+			ProgressPercent:      asset.ProgressPercent,
+			ProgressIteration:    asset.ProgressIteration,
+			ProgressEtaSeconds:   asset.ProgressEtaSeconds,
+			ProgressPreviewUrl:   asset.ProgressPreviewUrl,
+			ProgressUpdatedAt:    asset.ProgressUpdatedAt,
 			CreatedAt:            asset.CreatedAt,
 			UpdatedAt:            asset.UpdatedAt,
 		}
@@ -648,7 +659,7 @@ type removeAssetResponse struct {
 
 // RemoveAsset
 // @Summary Remove my asset
-// @Description Remove my asset
+// @Description Remove my asset. Its photos, point clouds, splats, segmentations and query outputs are deleted from the storage in the background, with retries; admins can list the files that could not be deleted at /admin/cleanup-jobs.
 // @Tags assets
 // @Accept json
 // @Produce json
@@ -669,16 +680,24 @@ func (server *Server) removeAsset(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.RemoveAssetParams{
-		Uid: payload.Uid,
-		ID:  uuid.MustParse(req.ID),
+	arg := db.RemoveAssetTxParams{
+		RemoveAssetParams: db.RemoveAssetParams{
+			Uid: payload.Uid,
+			ID:  uuid.MustParse(req.ID),
+		},
+		Paths:  server.assetArtifactPaths,
+		Notify: deletedWebhooks,
 	}
 
-	asset, err := server.store.RemoveAsset(ctx, arg)
+	// the files are deleted from the storage by the cleaner in the background
+	result, err := server.store.RemoveAssetTx(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	asset := result.Asset
+
+	server.cancelDeletedAsset(ctx, asset)
 
 	user, err := server.store.GetUserById(ctx, payload.Uid)
 	if err != nil {
@@ -690,7 +709,8 @@ func (server *Server) removeAsset(ctx *gin.Context) {
 }
 
 type UpdatePointCloudUrlRequest struct {
-	URL string `json:"url" binding:"required"`
+	URL   string `json:"url" binding:"required"`
+	JobID string `json:"jobId" binding:"omitempty,uuid"`
 }
 
 type UpdatePointCloudUrlParam struct {
@@ -707,6 +727,7 @@ type GenerateSplatEvent struct {
 	PhotoDirUrl  string `json:"photo_dir_url"`
 	PCLColmapUrl string `json:"pcl_colmap_url"`
 	Type         string `json:"type"`
+	JobID        string `json:"job_id"`
 }
 
 // UpdatePointCloudUrl updates the URL of a point cloud asset
@@ -717,7 +738,11 @@ type GenerateSplatEvent struct {
 // @Produce json
 // @Param   id   path   string     true  "Asset ID"
 // @Param   request  body   UpdatePointCloudUrlRequest     true  "Update Point Cloud URL Request"
+// @Param   Idempotency-Key  header  string  false  "Key that makes retrying the callback safe"
 // @Success 200 {object} UpdatePointCloudUrlResponse "URL updated successfully"
+// @Failure 409 {object} ErrorResponse "Error: Illegal status transition"
+// @Failure 410 {object} ErrorResponse "Error: Asset was cancelled"
+// @Security WorkerAuth
 // @Router /assets/pointcloud/{id} [patch]
 func (server *Server) updatePointCloudUrl(ctx *gin.Context) {
 	var req UpdatePointCloudUrlRequest
@@ -732,14 +757,18 @@ func (server *Server) updatePointCloudUrl(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.UpdatePointCloudUrlFromColmapParams{
-		ID:           uuid.MustParse(param.ID),
-		PclColmapUrl: sql.NullString{String: req.URL, Valid: true},
+	delivery, err := getCallbackDelivery(ctx, req.JobID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
 
-	asset, err := server.store.UpdatePointCloudUrlFromColmap(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	message := "update success"
+	asset, err := server.applyStageResult(ctx, uuid.MustParse(param.ID), pipeline.StageColmap, req.URL, delivery)
+	if errors.Is(err, errIgnoredCallback) {
+		message = err.Error()
+	} else if err != nil {
+		ctx.JSON(resultErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -749,22 +778,8 @@ func (server *Server) updatePointCloudUrl(ctx *gin.Context) {
 		return
 	}
 
-	if asset.Status == "generating sparse point cloud" {
-		arg := db.UpdateAssetStatusParams{
-			Uid:    user.Uid,
-			ID:     asset.ID,
-			Status: "generating 3d splat",
-		}
-
-		asset, err = server.store.UpdateAssetStatus(ctx, arg)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-	}
-
 	res := UpdatePointCloudUrlResponse{
-		Message: "update success",
+		Message: message,
 		Asset:   ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
 	}
 
@@ -772,7 +787,8 @@ func (server *Server) updatePointCloudUrl(ctx *gin.Context) {
 }
 
 type UpdateGaussianUrlRequest struct {
-	URL string `json:"url" binding:"required"`
+	URL   string `json:"url" binding:"required"`
+	JobID string `json:"jobId" binding:"omitempty,uuid"`
 }
 
 type UpdateGaussianUrlParam struct {
@@ -792,7 +808,11 @@ type UpdateGaussianUrlResponse struct {
 // @Produce json
 // @Param   id   path   string     true  "Asset ID"
 // @Param   request  body   UpdateGaussianUrlRequest     true  "Update Gaussian URL Request"
+// @Param   Idempotency-Key  header  string  false  "Key that makes retrying the callback safe"
 // @Success 200 {object} UpdateGaussianUrlResponse "URL updated successfully"
+// @Failure 409 {object} ErrorResponse "Error: Illegal status transition"
+// @Failure 410 {object} ErrorResponse "Error: Asset was cancelled"
+// @Security WorkerAuth
 // @Router /assets/gaussian/{id} [patch]
 func (server *Server) updateGaussianUrl(ctx *gin.Context) {
 	var req UpdateGaussianUrlRequest
@@ -807,20 +827,18 @@ func (server *Server) updateGaussianUrl(ctx *gin.Context) {
 		return
 	}
 
-	asset, err := server.store.GetAssetsById(ctx, uuid.MustParse(param.ID))
+	delivery, err := getCallbackDelivery(ctx, req.JobID)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	arg := db.UpdateSplatUrlParams{
-		ID:       uuid.MustParse(param.ID),
-		SplatUrl: sql.NullString{String: req.URL, Valid: true},
-	}
-
-	asset, err = server.store.UpdateSplatUrl(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	message := "update success"
+	asset, err := server.applyStageResult(ctx, uuid.MustParse(param.ID), pipeline.StageSplat, req.URL, delivery)
+	if errors.Is(err, errIgnoredCallback) {
+		message = err.Error()
+	} else if err != nil {
+		ctx.JSON(resultErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -830,22 +848,8 @@ func (server *Server) updateGaussianUrl(ctx *gin.Context) {
 		return
 	}
 
-	if asset.Status == "generating 3d splat" {
-		arg := db.UpdateAssetStatusParams{
-			Uid:    user.Uid,
-			ID:     asset.ID,
-			Status: "processing ptv3",
-		}
-
-		asset, err = server.store.UpdateAssetStatus(ctx, arg)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-	}
-
 	res := UpdateGaussianUrlResponse{
-		Message: "update success",
+		Message: message,
 		Asset:   ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
 	}
 
@@ -853,7 +857,8 @@ func (server *Server) updateGaussianUrl(ctx *gin.Context) {
 }
 
 type UpdatePTV3UrlRequest struct {
-	URL string `json:"url" binding:"required"`
+	URL   string `json:"url" binding:"required"`
+	JobID string `json:"jobId" binding:"omitempty,uuid"`
 }
 
 type UpdatePTV3UrlParam struct {
@@ -873,7 +878,11 @@ type UpdatePTV3UrlResponse struct {
 // @Produce json
 // @Param   id   path   string     true  "Asset ID"
 // @Param   request  body   UpdatePTV3UrlRequest     true  "Update PTv3 URL Request"
+// @Param   Idempotency-Key  header  string  false  "Key that makes retrying the callback safe"
 // @Success 200 {object} UpdatePTV3UrlResponse "URL updated successfully"
+// @Failure 409 {object} ErrorResponse "Error: Illegal status transition"
+// @Failure 410 {object} ErrorResponse "Error: Asset was cancelled"
+// @Security WorkerAuth
 // @Router /assets/ptv3/{id} [patch]
 func (server *Server) updatePTv3Url(ctx *gin.Context) {
 	var req UpdatePTV3UrlRequest
@@ -888,20 +897,18 @@ func (server *Server) updatePTv3Url(ctx *gin.Context) {
 		return
 	}
 
-	asset, err := server.store.GetAssetsById(ctx, uuid.MustParse(param.ID))
+	delivery, err := getCallbackDelivery(ctx, req.JobID)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	arg := db.UpdatePTvUrlParams{
-		ID:                 uuid.MustParse(param.ID),
-		SegmentedPclDirUrl: sql.NullString{String: req.URL, Valid: true},
-	}
-
-	asset, err = server.store.UpdatePTvUrl(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	message := "update success"
+	asset, err := server.applyStageResult(ctx, uuid.MustParse(param.ID), pipeline.StagePTv3, req.URL, delivery)
+	if errors.Is(err, errIgnoredCallback) {
+		message = err.Error()
+	} else if err != nil {
+		ctx.JSON(resultErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -911,22 +918,8 @@ func (server *Server) updatePTv3Url(ctx *gin.Context) {
 		return
 	}
 
-	if asset.Status == "processing ptv3" {
-		arg := db.UpdateAssetStatusParams{
-			Uid:    user.Uid,
-			ID:     asset.ID,
-			Status: "processing saga",
-		}
-
-		asset, err = server.store.UpdateAssetStatus(ctx, arg)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-	}
-
 	res := UpdatePTV3UrlResponse{
-		Message: "update success",
+		Message: message,
 		Asset:   ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
 	}
 
@@ -934,7 +927,8 @@ func (server *Server) updatePTv3Url(ctx *gin.Context) {
 }
 
 type UpdateSagaUrlRequest struct {
-	URL string `json:"url" binding:"required"`
+	URL   string `json:"url" binding:"required"`
+	JobID string `json:"jobId" binding:"omitempty,uuid"`
 }
 
 type UpdateSagaUrlParam struct {
@@ -954,7 +948,11 @@ type UpdateSagaUrlResponse struct {
 // @Produce json
 // @Param   id   path   string     true  "Asset ID"
 // @Param   request  body   UpdateSagaUrlRequest     true  "Update Saga URL Request"
+// @Param   Idempotency-Key  header  string  false  "Key that makes retrying the callback safe"
 // @Success 200 {object} UpdateSagaUrlResponse "URL updated successfully"
+// @Failure 409 {object} ErrorResponse "Error: Illegal status transition"
+// @Failure 410 {object} ErrorResponse "Error: Asset was cancelled"
+// @Security WorkerAuth
 // @Router /assets/saga/{id} [patch]
 func (server *Server) updateSagaUrl(ctx *gin.Context) {
 	var req UpdateSagaUrlRequest
@@ -969,20 +967,18 @@ func (server *Server) updateSagaUrl(ctx *gin.Context) {
 		return
 	}
 
-	asset, err := server.store.GetAssetsById(ctx, uuid.MustParse(param.ID))
+	delivery, err := getCallbackDelivery(ctx, req.JobID)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	arg := db.UpdateSagaUrlParams{
-		ID:                   uuid.MustParse(param.ID),
-		SegmentedSplatDirUrl: sql.NullString{String: req.URL, Valid: true},
-	}
-
-	asset, err = server.store.UpdateSagaUrl(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	message := "update success"
+	asset, err := server.applyStageResult(ctx, uuid.MustParse(param.ID), pipeline.StageSaga, req.URL, delivery)
+	if errors.Is(err, errIgnoredCallback) {
+		message = err.Error()
+	} else if err != nil {
+		ctx.JSON(resultErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -992,22 +988,8 @@ func (server *Server) updateSagaUrl(ctx *gin.Context) {
 		return
 	}
 
-	if asset.Status == "processing saga" {
-		arg := db.UpdateAssetStatusParams{
-			Uid:    user.Uid,
-			ID:     asset.ID,
-			Status: "completed",
-		}
-
-		asset, err = server.store.UpdateAssetStatus(ctx, arg)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-	}
-
 	res := UpdateSagaUrlResponse{
-		Message: "update success",
+		Message: message,
 		Asset:   ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
 	}
 
@@ -1164,7 +1146,7 @@ type SegmentUsingSagaRequest struct {
 }
 
 type SegmentUsingSagaParam struct {
-	ID string `uri:"id" binding:"required"`
+	ID string `uri:"id" binding:"required,uuid"`
 }
 
 type SegmentUsingSagaResponse struct {
@@ -1181,6 +1163,8 @@ type SegmentUsingSagaResponse struct {
 // @Param   id   path   string     true  "Asset ID"
 // @Param   request  body   SegmentUsingSagaRequest     true  "Segment using SAGA Request"
 // @Success 200 {object} SegmentUsingSagaResponse "Segment using SAGA successfully"
+// @Failure 404 {object} ErrorResponse "Error: Asset not found"
+// @Security BearerAuth
 // @Router /assets/saga/segment/{id} [post]
 func (server *Server) segmentUsingSaga(ctx *gin.Context) {
 	var req SegmentUsingSagaRequest
@@ -1191,21 +1175,36 @@ func (server *Server) segmentUsingSaga(ctx *gin.Context) {
 
 	var param SegmentUsingSagaParam
 	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, err := getUserPayload(ctx)
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
 	asset, err := server.store.GetAssetsById(ctx, uuid.MustParse(param.ID))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("asset is not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	// private assets are only segmented for their owner, like in the viewer
+	if asset.IsPrivate && asset.Uid != payload.Uid {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("asset is not found")))
 		return
 	}
 
 	err = publishSegmentUsingSagaEvent(server, GenerateSegmentUsingSagaEvent{
-		AssetID: asset.ID.String(),
-		X: req.X,
-		Y: req.Y,
-		URL: req.URL,
+		AssetID:          asset.ID.String(),
+		X:                req.X,
+		Y:                req.Y,
+		URL:              req.URL,
 		UniqueIdentifier: req.UniqueIdentifier,
 	})
 
@@ -1214,9 +1213,9 @@ func (server *Server) segmentUsingSaga(ctx *gin.Context) {
 		return
 	}
 
-	res := SegmentUsingSagaResponse {
+	res := SegmentUsingSagaResponse{
 		Message: "success",
-		Url: fmt.Sprintf("/files/%s/%s.ply", asset.ID.String(), req.UniqueIdentifier),
+		Url:     fmt.Sprintf("/files/%s/%s.ply", asset.ID.String(), req.UniqueIdentifier),
 	}
 
 	ctx.JSON(http.StatusOK, res)
@@ -1238,7 +1237,7 @@ func publishSegmentUsingSagaEvent(server *Server, message GenerateSegmentUsingSa
 		return err
 	}
 
-	err = server.rabbitmq.PublishEvent("query", msg)
+	err = server.broker.PublishEvent(queryQueue, msg, 0)
 	if err != nil {
 		return err
 	}
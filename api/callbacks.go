@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+)
+
+// idempotencyKeyHeader carries the key a worker picks for a callback so the
+// callback can be retried safely.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+var (
+	// errIgnoredCallback is returned for a callback that was delivered before
+	// or that reports on an attempt which is no longer running. Such
+	// callbacks are acknowledged without being applied.
+	errIgnoredCallback   = errors.New("callback ignored")
+	errDuplicateCallback = fmt.Errorf("%w: it was already processed", errIgnoredCallback)
+	errStaleCallback     = fmt.Errorf("%w: its job is no longer running", errIgnoredCallback)
+)
+
+// callbackDelivery identifies one delivery of a worker callback, over HTTP or
+// through the results queue. Callbacks without a job id or key are applied as
+// they come, as they were before workers sent either.
+type callbackDelivery struct {
+	WorkerID string
+	// JobID is the job attempt the callback reports on. It is sent to the
+	// worker in the stage message.
+	JobID uuid.NullUUID
+	// IdempotencyKey is stored once the callback is applied, so a second
+	// delivery with the same key is ignored.
+	IdempotencyKey string
+}
+
+func newCallbackDelivery(workerID string, jobID string, key string) (callbackDelivery, error) {
+	delivery := callbackDelivery{WorkerID: workerID, IdempotencyKey: key}
+	if len(jobID) > 0 {
+		id, err := uuid.Parse(jobID)
+		if err != nil {
+			return delivery, fmt.Errorf("invalid job id %q: %w", jobID, err)
+		}
+		delivery.JobID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	return delivery, nil
+}
+
+// getCallbackDelivery reads the delivery of an HTTP callback from the worker
+// token and the Idempotency-Key header.
+func getCallbackDelivery(ctx *gin.Context, jobID string) (callbackDelivery, error) {
+	workerID, err := getWorkerID(ctx)
+	if err != nil {
+		return callbackDelivery{}, err
+	}
+
+	return newCallbackDelivery(workerID, jobID, ctx.GetHeader(idempotencyKeyHeader))
+}
+
+// claimDelivery checks that the callback reports on the running attempt of
+// the stage and records its key. errIgnoredCallback is returned when the job
+// has already finished or the key was seen before.
+func (server *Server) claimDelivery(ctx context.Context, asset db.Assets, stage pipeline.Stage, delivery callbackDelivery) (db.Jobs, error) {
+	var job db.Jobs
+	if delivery.JobID.Valid {
+		var err error
+		job, err = server.store.GetJobById(ctx, delivery.JobID.UUID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return job, fmt.Errorf("%w: job %s is not known", pipeline.ErrIllegalTransition, delivery.JobID.UUID)
+			}
+			return job, err
+		}
+
+		if job.AssetsId != asset.ID || job.Stage != string(stage) {
+			return job, fmt.Errorf("%w: job %s does not run %s of this asset", pipeline.ErrIllegalTransition, job.ID, stage)
+		}
+
+		if job.FinishedAt.Valid {
+			return job, errStaleCallback
+		}
+	}
+
+	if len(delivery.IdempotencyKey) == 0 {
+		return job, nil
+	}
+
+	_, err := server.store.CreateProcessedCallback(ctx, db.CreateProcessedCallbackParams{
+		Key:      delivery.IdempotencyKey,
+		AssetsId: asset.ID,
+		WorkerId: delivery.WorkerID,
+	})
+	if err == sql.ErrNoRows {
+		return job, errDuplicateCallback
+	}
+
+	return job, err
+}
+
+// releaseDelivery forgets the key of a callback that could not be applied, so
+// the worker can deliver it again.
+func (server *Server) releaseDelivery(ctx context.Context, delivery callbackDelivery) {
+	if len(delivery.IdempotencyKey) == 0 {
+		return
+	}
+
+	err := server.store.DeleteProcessedCallback(ctx, delivery.IdempotencyKey)
+	if err != nil {
+		log.Printf("can't release idempotency key %q: %v", delivery.IdempotencyKey, err)
+	}
+}
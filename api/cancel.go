@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+)
+
+const controlCancel = "cancel"
+
+// CancelAssetEvent is broadcast on the control exchange to tell the workers to
+// stop processing an asset. Stage is the stage that was running, if any.
+type CancelAssetEvent struct {
+	AssetID string `json:"asset_id"`
+	Stage   string `json:"stage,omitempty"`
+	Type    string `json:"type"`
+}
+
+type CancelAssetParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type CancelAssetResponse struct {
+	Message string        `json:"message"`
+	Asset   AssetResponse `json:"asset"`
+}
+
+// CancelAsset stops the processing of an asset
+// @Summary Cancel asset processing
+// @Description Moves an asset that is still being processed to the cancelled state and tells the workers to stop. Results reported for the asset afterwards are rejected. A cancelled asset can be reprocessed later.
+// @Tags assets
+// @Produce json
+// @Param   id   path   string     true  "Asset ID"
+// @Success 202 {object} CancelAssetResponse "Asset processing cancelled"
+// @Failure 403 {object} ErrorResponse "Error: Not the owner of the asset"
+// @Failure 409 {object} ErrorResponse "Error: Asset is not being processed"
+// @Security BearerAuth
+// @Router /assets/{id}/cancel [post]
+func (server *Server) cancelAsset(ctx *gin.Context) {
+	payload, err := getUserPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var param CancelAssetParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	asset, err := server.store.GetAssetsById(ctx, uuid.MustParse(param.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if asset.Uid != payload.Uid {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you are not the owner of this asset")))
+		return
+	}
+
+	if pipeline.State(asset.Status).IsTerminal() {
+		ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("asset is not being processed (%s)", asset.Status)))
+		return
+	}
+
+	event, err := cancelEvent(asset)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	asset, err = server.applyTransition(ctx, asset, pipeline.StateCancelled, db.TransitionAssetTxParams{
+		Event: &event,
+		CloseJobs: &db.CloseOpenJobsParams{
+			AssetsId: asset.ID,
+			Status:   jobStatusCancelled,
+			Error:    sql.NullString{String: "cancelled by the owner", Valid: true},
+		},
+	})
+	if err != nil {
+		ctx.JSON(transitionErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserById(ctx, asset.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := CancelAssetResponse{
+		Message: "asset processing cancelled",
+		Asset:   ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
+	}
+
+	ctx.JSON(http.StatusAccepted, res)
+}
+
+// cancelEvent builds the outbox message that tells the workers to stop
+// processing the asset.
+func cancelEvent(asset db.Assets) (db.CreateOutboxEventParams, error) {
+	event := CancelAssetEvent{
+		AssetID: asset.ID.String(),
+		Type:    controlCancel,
+	}
+	if stage, ok := pipeline.StageOf(pipeline.State(asset.Status)); ok {
+		event.Stage = string(stage)
+	}
+
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return db.CreateOutboxEventParams{}, err
+	}
+
+	return db.CreateOutboxEventParams{Exchange: pipeline.ControlExchange, Payload: msg}, nil
+}
+
+// cancelDeletedAsset tells the workers to drop a deleted asset that was still
+// being processed.
+func (server *Server) cancelDeletedAsset(ctx context.Context, asset db.Assets) {
+	if pipeline.State(asset.Status).IsTerminal() {
+		return
+	}
+
+	event, err := cancelEvent(asset)
+	if err == nil {
+		_, err = server.store.CreateOutboxEvent(ctx, event)
+	}
+	if err != nil {
+		log.Printf("can't queue cancel message for deleted asset %s: %v", asset.ID, err)
+	}
+}
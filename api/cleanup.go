@@ -0,0 +1,169 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segment3d-app/segment3d-be/cleanup"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+)
+
+// defaultCleanupJobLimit is the number of cleanup jobs listed at most.
+const defaultCleanupJobLimit = 100
+
+// assetArtifactPaths lists every storage path the asset and its jobs point
+// at: the photos, point clouds, splats, segmentations, logs and previews, and
+// the directory the SAGA query outputs are written to. Only paths within the
+// storage of the owner or the output directory of the asset are deleted. The
+// rest, such as urls outside of the storage or files of other users a worker
+// reported, are returned as orphaned for an admin to look at.
+func (server *Server) assetArtifactPaths(asset db.Assets, jobs []db.Jobs) ([]string, []string) {
+	urls := []string{
+		asset.ThumbnailUrl,
+		asset.PhotoDirUrl,
+		asset.SplatUrl.String,
+		asset.PclUrl.String,
+		asset.PclColmapUrl.String,
+		asset.SegmentedPclDirUrl.String,
+		asset.SegmentedSplatDirUrl.String,
+		asset.FailureLogsUrl.String,
+		asset.ProgressPreviewUrl.String,
+	}
+	for _, job := range jobs {
+		urls = append(urls, job.ResultUrl.String)
+	}
+
+	seen := make(map[string]bool)
+	paths := []string{}
+	orphaned := []string{}
+	add := func(list *[]string, p string) {
+		if !seen[p] {
+			seen[p] = true
+			*list = append(*list, p)
+		}
+	}
+
+	for _, url := range urls {
+		if len(url) == 0 {
+			continue
+		}
+		p, err := server.storagePath(url)
+		if err != nil {
+			add(&orphaned, url)
+			continue
+		}
+		if !assetOwnsPath(asset, p) {
+			add(&orphaned, p)
+			continue
+		}
+		add(&paths, p)
+	}
+	add(&paths, sagaOutputDir(asset))
+	sort.Strings(paths)
+	sort.Strings(orphaned)
+
+	return paths, orphaned
+}
+
+// assetOwnsPath reports whether the storage path is below the storage of the
+// asset owner or is the output directory of the asset, so deleting it cannot
+// take files of other users with it.
+func assetOwnsPath(asset db.Assets, p string) bool {
+	dir := sagaOutputDir(asset)
+	return strings.HasPrefix(p, userStoragePrefix(asset.Uid)) || p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// sagaOutputDir is where the workers write the SAGA query results of the
+// asset.
+func sagaOutputDir(asset db.Assets) string {
+	return fmt.Sprintf("/files/%s", asset.ID)
+}
+
+type CleanupJobResponse struct {
+	ID            string     `json:"id"`
+	AssetID       string     `json:"assetId"`
+	Uid           string     `json:"uid"`
+	Paths         []string   `json:"paths"`
+	Orphaned      []string   `json:"orphaned"`
+	Status        string     `json:"status"`
+	Attempts      int32      `json:"attempts"`
+	LastError     string     `json:"lastError"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	CompletedAt   *time.Time `json:"completedAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+func ReturnCleanupJobResponse(job *db.CleanupJobs) CleanupJobResponse {
+	res := CleanupJobResponse{
+		ID:            job.ID.String(),
+		AssetID:       job.AssetsId.String(),
+		Uid:           job.Uid.String(),
+		Paths:         job.Paths,
+		Orphaned:      append(job.Remaining, job.Orphaned...),
+		Status:        job.Status,
+		Attempts:      job.Attempts,
+		LastError:     job.LastError.String,
+		NextAttemptAt: job.NextAttemptAt,
+		CreatedAt:     job.CreatedAt,
+	}
+	if job.CompletedAt.Valid {
+		res.CompletedAt = &job.CompletedAt.Time
+	}
+
+	return res
+}
+
+type listCleanupJobsQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending done failed"`
+}
+
+type listCleanupJobsResponse struct {
+	Message     string               `json:"message"`
+	CleanupJobs []CleanupJobResponse `json:"cleanupJobs"`
+}
+
+// ListCleanupJobs reports the files of removed assets
+// @Summary List cleanup jobs
+// @Description Lists the jobs that delete the files of removed assets, newest first, at most 100. By default only failed jobs are listed, whose orphaned paths could not be deleted from the storage after every retry or were left in place since they are outside of the storage of the asset owner and the output directory of the asset.
+// @Tags admin
+// @Produce json
+// @Param   status   query   string  false  "pending, done or failed, defaults to failed"
+// @Success 200 {object} listCleanupJobsResponse "Cleanup jobs retrieved"
+// @Failure 403 {object} ErrorResponse "Error: Admin role is required"
+// @Security BearerAuth
+// @Router /admin/cleanup-jobs [get]
+func (server *Server) listCleanupJobs(ctx *gin.Context) {
+	var query listCleanupJobsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	status := query.Status
+	if len(status) == 0 {
+		status = cleanup.StatusFailed
+	}
+
+	jobs, err := server.store.ListCleanupJobsByStatus(ctx, db.ListCleanupJobsByStatusParams{
+		Status: status,
+		Limit:  defaultCleanupJobLimit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := listCleanupJobsResponse{
+		Message:     "cleanup jobs retrieved",
+		CleanupJobs: []CleanupJobResponse{},
+	}
+	for i := range jobs {
+		res.CleanupJobs = append(res.CleanupJobs, ReturnCleanupJobResponse(&jobs[i]))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
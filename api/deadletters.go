@@ -0,0 +1,233 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/rabbitmq"
+)
+
+// defaultDeadLetterLimit is the number of dead letters listed when no asset
+// is given.
+const defaultDeadLetterLimit = 100
+
+type DeadLetterResponse struct {
+	ID         string    `json:"id"`
+	Queue      string    `json:"queue"`
+	AssetID    string    `json:"assetId"`
+	Reason     string    `json:"reason"`
+	Error      string    `json:"error"`
+	DeathCount int32     `json:"deathCount"`
+	Payload    string    `json:"payload"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func ReturnDeadLetterResponse(letter *db.DeadLetters) DeadLetterResponse {
+	res := DeadLetterResponse{
+		ID:         letter.ID.String(),
+		Queue:      letter.Queue,
+		Reason:     letter.Reason,
+		Error:      letter.Error.String,
+		DeathCount: letter.DeathCount,
+		Payload:    letter.Payload,
+		CreatedAt:  letter.CreatedAt,
+	}
+	if letter.AssetsId.Valid {
+		res.AssetID = letter.AssetsId.UUID.String()
+	}
+
+	return res
+}
+
+// ConsumeDeadLetters stores the messages dead-lettered from the queues the
+// server publishes to and consumes, so admins can inspect and replay them.
+func (server *Server) ConsumeDeadLetters() error {
+	queues := append(PublishedQueues(server.pipelines), ConsumedQueues()...)
+	for _, queue := range queues {
+		err := server.broker.ConsumeDeadLetters(queue, server.storeDeadLetter)
+		if err != nil {
+			return fmt.Errorf("can't consume dead letters of %s: %w", queue, err)
+		}
+	}
+
+	return nil
+}
+
+func (server *Server) storeDeadLetter(ctx context.Context, letter rabbitmq.DeadLetter) error {
+	arg := db.CreateDeadLetterParams{
+		Queue:      letter.Queue,
+		Reason:     letter.Reason,
+		Error:      sql.NullString{String: letter.Error, Valid: len(letter.Error) > 0},
+		DeathCount: int32(letter.Count),
+		Payload:    string(letter.Body),
+	}
+
+	// every pipeline and query message carries the asset it belongs to, but
+	// the body of a rejected message may be anything
+	var body struct {
+		AssetID string `json:"asset_id"`
+	}
+	if err := json.Unmarshal(letter.Body, &body); err == nil {
+		if id, err := uuid.Parse(body.AssetID); err == nil {
+			_, err := server.store.GetAssetsById(ctx, id)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			arg.AssetsId = uuid.NullUUID{UUID: id, Valid: err == nil}
+		}
+	}
+
+	_, err := server.store.CreateDeadLetter(ctx, arg)
+	return err
+}
+
+type listDeadLettersQuery struct {
+	AssetID string `form:"assetId" binding:"omitempty,uuid"`
+}
+
+type listDeadLettersResponse struct {
+	Message     string               `json:"message"`
+	DeadLetters []DeadLetterResponse `json:"deadLetters"`
+}
+
+// ListDeadLetters lists dead-lettered messages
+// @Summary List dead letters
+// @Description Lists messages that were rejected by a worker or the backend, newest first. Without an asset id only the latest 100 are returned.
+// @Tags admin
+// @Produce json
+// @Param   assetId   query   string  false  "Asset ID"
+// @Success 200 {object} listDeadLettersResponse "Dead letters retrieved"
+// @Failure 403 {object} ErrorResponse "Error: Admin role is required"
+// @Security BearerAuth
+// @Router /admin/dead-letters [get]
+func (server *Server) listDeadLetters(ctx *gin.Context) {
+	var query listDeadLettersQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var letters []db.DeadLetters
+	var err error
+	if len(query.AssetID) > 0 {
+		letters, err = server.store.ListDeadLettersByAsset(ctx, uuid.NullUUID{UUID: uuid.MustParse(query.AssetID), Valid: true})
+	} else {
+		letters, err = server.store.ListDeadLetters(ctx, defaultDeadLetterLimit)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := listDeadLettersResponse{
+		Message:     "dead letters retrieved",
+		DeadLetters: []DeadLetterResponse{},
+	}
+	for i := range letters {
+		res.DeadLetters = append(res.DeadLetters, ReturnDeadLetterResponse(&letters[i]))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type deadLetterParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type deadLetterResponse struct {
+	Message    string             `json:"message"`
+	DeadLetter DeadLetterResponse `json:"deadLetter"`
+}
+
+// ReplayDeadLetter publishes a dead letter again
+// @Summary Replay dead letter
+// @Description Publishes the dead-lettered message to its original queue and removes it from the dead letters.
+// @Tags admin
+// @Produce json
+// @Param   id   path   string  true  "Dead letter ID"
+// @Success 200 {object} deadLetterResponse "Dead letter replayed"
+// @Failure 403 {object} ErrorResponse "Error: Admin role is required"
+// @Failure 404 {object} ErrorResponse "Error: Dead letter not found"
+// @Security BearerAuth
+// @Router /admin/dead-letters/{id}/replay [post]
+func (server *Server) replayDeadLetter(ctx *gin.Context) {
+	letter, ok := server.bindDeadLetter(ctx)
+	if !ok {
+		return
+	}
+
+	err := server.broker.PublishEvent(letter.Queue, []byte(letter.Payload), 0)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.store.DeleteDeadLetter(ctx, letter.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deadLetterResponse{
+		Message:    fmt.Sprintf("dead letter replayed to %s", letter.Queue),
+		DeadLetter: ReturnDeadLetterResponse(&letter),
+	})
+}
+
+// DiscardDeadLetter removes a dead letter
+// @Summary Discard dead letter
+// @Description Removes the dead-lettered message without publishing it again.
+// @Tags admin
+// @Produce json
+// @Param   id   path   string  true  "Dead letter ID"
+// @Success 200 {object} deadLetterResponse "Dead letter discarded"
+// @Failure 403 {object} ErrorResponse "Error: Admin role is required"
+// @Failure 404 {object} ErrorResponse "Error: Dead letter not found"
+// @Security BearerAuth
+// @Router /admin/dead-letters/{id} [delete]
+func (server *Server) discardDeadLetter(ctx *gin.Context) {
+	letter, ok := server.bindDeadLetter(ctx)
+	if !ok {
+		return
+	}
+
+	err := server.store.DeleteDeadLetter(ctx, letter.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deadLetterResponse{
+		Message:    "dead letter discarded",
+		DeadLetter: ReturnDeadLetterResponse(&letter),
+	})
+}
+
+// bindDeadLetter loads the dead letter named in the uri and writes the error
+// response when that fails.
+func (server *Server) bindDeadLetter(ctx *gin.Context) (db.DeadLetters, bool) {
+	var param deadLetterParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.DeadLetters{}, false
+	}
+
+	letter, err := server.store.GetDeadLetterById(ctx, uuid.MustParse(param.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("dead letter is not found")))
+			return letter, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return letter, false
+	}
+
+	return letter, true
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/hub"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+)
+
+const (
+	// assetEventSnapshot carries the asset as it was when the stream opened.
+	assetEventSnapshot = "snapshot"
+	// assetEventStatus is sent on every status transition.
+	assetEventStatus = "status"
+	// assetEventOutput is sent when a stage stored its output url.
+	assetEventOutput = "output"
+	// assetEventSegmentation is sent when the SAGA segmentation finished.
+	assetEventSegmentation = "segmentation"
+	// assetEventResync ends a stream that fell behind and missed events. The
+	// client reconnects and starts over from a fresh snapshot.
+	assetEventResync = "resync"
+
+	// eventKeepAliveInterval keeps proxies from closing idle streams.
+	eventKeepAliveInterval = 15 * time.Second
+)
+
+type AssetStatusEvent struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type AssetOutputEvent struct {
+	Stage string `json:"stage"`
+	Url   string `json:"url"`
+}
+
+// publishAssetEvent notifies the subscribers of the asset.
+func (server *Server) publishAssetEvent(eventType string, assetID uuid.UUID, data any) {
+	server.hub.Publish(hub.Event{Type: eventType, AssetID: assetID, Data: data})
+}
+
+// publishStageOutput notifies the subscribers that a stage stored its output.
+func (server *Server) publishStageOutput(asset db.Assets, stage pipeline.Stage, url string) {
+	eventType := assetEventOutput
+	if stage == pipeline.StageSaga {
+		eventType = assetEventSegmentation
+	}
+
+	server.publishAssetEvent(eventType, asset.ID, AssetOutputEvent{Stage: string(stage), Url: url})
+}
+
+type streamAssetEventsParam struct {
+	// the wildcard has to share its name with GET /api/assets/:slug
+	ID string `uri:"slug" binding:"required,uuid"`
+}
+
+// StreamAssetEvents streams the events of an asset
+// @Summary Stream asset events
+// @Description Opens a Server-Sent Events stream of an asset. The first event is a snapshot of the asset, followed by status transitions, progress reported by the workers, stage outputs and SAGA segmentation completions. A client that falls behind receives a resync event and the stream ends, so it reconnects and starts over from a new snapshot. Private assets can only be streamed by their owner.
+// @Tags assets
+// @Produce text/event-stream
+// @Param   id   path   string  true  "Asset ID"
+// @Success 200 {object} hub.Event "Stream of asset events"
+// @Failure 404 {object} ErrorResponse "Error: Asset not found"
+// @Security BearerAuth
+// @Router /assets/{id}/events [get]
+func (server *Server) streamAssetEvents(ctx *gin.Context) {
+	var param streamAssetEventsParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	asset, err := server.store.GetAssetsById(ctx, uuid.MustParse(param.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("asset is not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// private assets are reported as missing to anyone but their owner
+	if asset.IsPrivate {
+		payload, err := getUserPayload(ctx)
+		if err != nil || payload.Uid != asset.Uid {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("asset is not found")))
+			return
+		}
+	}
+
+	user, err := server.store.GetUserById(ctx, asset.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// subscribe before sending the snapshot so no event is lost in between
+	events, unsubscribe := server.hub.Subscribe(asset.ID)
+	defer unsubscribe()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	snapshot := hub.Event{
+		Type:    assetEventSnapshot,
+		AssetID: asset.ID,
+		Data:    ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
+	}
+	ctx.SSEvent(snapshot.Type, snapshot)
+	ctx.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-keepAlive.C:
+			// comment lines are ignored by EventSource clients
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case event, ok := <-events:
+			if !ok {
+				// the hub dropped the subscription since the client fell
+				// behind
+				ctx.SSEvent(assetEventResync, hub.Event{Type: assetEventResync, AssetID: asset.ID})
+				return false
+			}
+			ctx.SSEvent(event.Type, event)
+			return true
+		}
+	})
+}
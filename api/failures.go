@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+)
+
+type ReportAssetFailureRequest struct {
+	Stage   string `json:"stage" binding:"required,oneof=colmap splat ptv3 saga"`
+	Error   string `json:"error" binding:"required"`
+	LogsUrl string `json:"logsUrl"`
+	JobID   string `json:"jobId" binding:"omitempty,uuid"`
+}
+
+type ReportAssetFailureParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type ReportAssetFailureResponse struct {
+	Message string        `json:"message"`
+	Asset   AssetResponse `json:"asset"`
+}
+
+// ReportAssetFailure marks an asset as failed
+// @Summary Report pipeline failure
+// @Description Called by a GPU worker when COLMAP, Gaussian splatting, PTv3 or SAGA crashed. Moves the asset to the failed state and stores the reason.
+// @Tags assets
+// @Accept json
+// @Produce json
+// @Param   id   path   string     true  "Asset ID"
+// @Param   request  body   ReportAssetFailureRequest     true  "Report Asset Failure Request"
+// @Param   Idempotency-Key  header  string  false  "Key that makes retrying the callback safe"
+// @Success 200 {object} ReportAssetFailureResponse "Failure recorded successfully"
+// @Failure 409 {object} ErrorResponse "Error: Illegal status transition"
+// @Failure 410 {object} ErrorResponse "Error: Asset was cancelled"
+// @Security WorkerAuth
+// @Router /assets/failure/{id} [patch]
+func (server *Server) reportAssetFailure(ctx *gin.Context) {
+	var req ReportAssetFailureRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var param ReportAssetFailureParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	delivery, err := getCallbackDelivery(ctx, req.JobID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	message := "failure recorded"
+	asset, err := server.applyStageFailure(ctx, uuid.MustParse(param.ID), pipeline.Stage(req.Stage), req.Error, req.LogsUrl, delivery)
+	if errors.Is(err, errIgnoredCallback) {
+		message = err.Error()
+	} else if err != nil {
+		ctx.JSON(resultErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserById(ctx, asset.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	res := ReportAssetFailureResponse{
+		Message: message,
+		Asset:   ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// markAssetFailed moves the asset to the failed state and stores why the
+// stage failed. The reason is written in the same transaction as the status,
+// so it is never left on an asset another callback moved on meanwhile. arg
+// carries the rest of the work of the transition, such as the job a worker
+// reported the failure for.
+func (server *Server) markAssetFailed(ctx context.Context, asset db.Assets, stage pipeline.Stage, reason string, logsUrl string, arg db.TransitionAssetTxParams) (db.Assets, error) {
+	arg.Failure = &db.UpdateAssetFailureParams{
+		ID:             asset.ID,
+		FailureStage:   sql.NullString{String: string(stage), Valid: true},
+		FailureReason:  sql.NullString{String: reason, Valid: true},
+		FailureLogsUrl: sql.NullString{String: logsUrl, Valid: len(logsUrl) > 0},
+	}
+	arg.CloseJobs = &db.CloseOpenJobsParams{
+		AssetsId: asset.ID,
+		Status:   jobStatusFailed,
+		Error:    sql.NullString{String: reason, Valid: true},
+	}
+
+	return server.applyTransition(ctx, asset, pipeline.StateFailed, arg)
+}
+
+// failAsset marks the asset failed when the backend itself could not start a
+// stage. Errors are only logged since the caller is already reporting one.
+func (server *Server) failAsset(ctx context.Context, asset db.Assets, stage pipeline.Stage, cause error) {
+	_, err := server.markAssetFailed(ctx, asset, stage, cause.Error(), "", db.TransitionAssetTxParams{})
+	if err != nil {
+		log.Printf("can't mark asset %s as failed: %v", asset.ID, err)
+	}
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+)
+
+const (
+	jobStatusSucceeded = "succeeded"
+	jobStatusFailed    = "failed"
+	jobStatusCancelled = "cancelled"
+)
+
+type JobResponse struct {
+	ID         string     `json:"id"`
+	Stage      string     `json:"stage"`
+	Attempt    int32      `json:"attempt"`
+	Status     string     `json:"status"`
+	WorkerID   string     `json:"workerId"`
+	ResultUrl  string     `json:"resultUrl"`
+	Error      string     `json:"error"`
+	EnqueuedAt time.Time  `json:"enqueuedAt"`
+	StartedAt  *time.Time `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+	// WaitSeconds is the time spent queued before a worker picked the job up.
+	WaitSeconds float64 `json:"waitSeconds"`
+	// RunSeconds is the time the worker spent on the job.
+	RunSeconds float64 `json:"runSeconds"`
+}
+
+func ReturnJobResponse(job *db.Jobs) JobResponse {
+	res := JobResponse{
+		ID:         job.ID.String(),
+		Stage:      job.Stage,
+		Attempt:    job.Attempt,
+		Status:     job.Status,
+		WorkerID:   job.WorkerId.String,
+		ResultUrl:  job.ResultUrl.String,
+		Error:      job.Error.String,
+		EnqueuedAt: job.EnqueuedAt,
+	}
+	if job.StartedAt.Valid {
+		res.StartedAt = &job.StartedAt.Time
+		res.WaitSeconds = job.StartedAt.Time.Sub(job.EnqueuedAt).Seconds()
+	}
+	if job.FinishedAt.Valid {
+		res.FinishedAt = &job.FinishedAt.Time
+		if job.StartedAt.Valid {
+			res.RunSeconds = job.FinishedAt.Time.Sub(job.StartedAt.Time).Seconds()
+		}
+	}
+
+	return res
+}
+
+// finishJobParams closes the job of the stage the callback reports on. The
+// job the delivery names is the only one it may close.
+func finishJobParams(assetID uuid.UUID, stage pipeline.Stage, status string, delivery callbackDelivery, resultUrl string, reason string) (*db.FinishJobParams, uuid.NullUUID) {
+	return &db.FinishJobParams{
+		AssetsId:  assetID,
+		Stage:     string(stage),
+		Status:    status,
+		WorkerId:  sql.NullString{String: delivery.WorkerID, Valid: len(delivery.WorkerID) > 0},
+		ResultUrl: sql.NullString{String: resultUrl, Valid: len(resultUrl) > 0},
+		Error:     sql.NullString{String: reason, Valid: len(reason) > 0},
+	}, delivery.JobID
+}
+
+// applyStageStart records that a worker picked up the stage of the asset. The
+// job is returned along with errIgnoredCallback when it was already started.
+func (server *Server) applyStageStart(ctx context.Context, assetID uuid.UUID, stage pipeline.Stage, delivery callbackDelivery) (job db.Jobs, err error) {
+	asset, err := server.store.GetAssetsById(ctx, assetID)
+	if err != nil {
+		return db.Jobs{}, err
+	}
+
+	if pipeline.State(asset.Status) == pipeline.StateCancelled {
+		return db.Jobs{}, pipeline.ErrCancelled
+	}
+
+	job, err = server.claimDelivery(ctx, asset, stage, delivery)
+	if err != nil {
+		return job, err
+	}
+	defer func() {
+		if err != nil && !errors.Is(err, errIgnoredCallback) {
+			server.releaseDelivery(ctx, delivery)
+		}
+	}()
+
+	if delivery.JobID.Valid && job.StartedAt.Valid {
+		return job, errDuplicateCallback
+	}
+
+	if pipeline.State(asset.Status) != stage.State() {
+		return db.Jobs{}, fmt.Errorf("%w: asset is %q, not running %s", pipeline.ErrIllegalTransition, asset.Status, stage)
+	}
+
+	return server.store.StartJob(ctx, db.StartJobParams{
+		AssetsId: assetID,
+		Stage:    string(stage),
+		WorkerId: sql.NullString{String: delivery.WorkerID, Valid: true},
+	})
+}
+
+type ReportStageStartRequest struct {
+	Stage string `json:"stage" binding:"required,oneof=colmap splat ptv3 saga"`
+	JobID string `json:"jobId" binding:"omitempty,uuid"`
+}
+
+type ReportStageStartParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type ReportStageStartResponse struct {
+	Message string      `json:"message"`
+	Job     JobResponse `json:"job"`
+}
+
+// ReportStageStart records that a worker started a stage
+// @Summary Report stage start
+// @Description Called by a GPU worker when it picks up a stage of an asset, so the wait and run time of the job can be told apart
+// @Tags assets
+// @Accept json
+// @Produce json
+// @Param   id   path   string     true  "Asset ID"
+// @Param   request  body   ReportStageStartRequest     true  "Report Stage Start Request"
+// @Param   Idempotency-Key  header  string  false  "Key that makes retrying the callback safe"
+// @Success 200 {object} ReportStageStartResponse "Job started"
+// @Failure 404 {object} ErrorResponse "Error: Asset or job not found"
+// @Failure 409 {object} ErrorResponse "Error: Asset is not running the stage"
+// @Failure 410 {object} ErrorResponse "Error: Asset was cancelled"
+// @Security WorkerAuth
+// @Router /assets/start/{id} [patch]
+func (server *Server) reportStageStart(ctx *gin.Context) {
+	var req ReportStageStartRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var param ReportStageStartParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	delivery, err := getCallbackDelivery(ctx, req.JobID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	message := "job started"
+	job, err := server.applyStageStart(ctx, uuid.MustParse(param.ID), pipeline.Stage(req.Stage), delivery)
+	if errors.Is(err, errIgnoredCallback) {
+		message = err.Error()
+	} else if err != nil {
+		ctx.JSON(resultErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ReportStageStartResponse{Message: message, Job: ReturnJobResponse(&job)})
+}
+
+type getAssetJobsParam struct {
+	// the wildcard has to share its name with GET /api/assets/:slug
+	ID string `uri:"slug" binding:"required,uuid"`
+}
+
+type getAssetJobsResponse struct {
+	Message string        `json:"message"`
+	Jobs    []JobResponse `json:"jobs"`
+}
+
+// GetAssetJobs lists the processing jobs of an asset
+// @Summary Get asset jobs
+// @Description Lists every pipeline stage run of an asset in the order they were enqueued, with attempts, workers and timing. Only the owner and admins can see them.
+// @Tags assets
+// @Produce json
+// @Param   id   path   string  true  "Asset ID"
+// @Success 200 {object} getAssetJobsResponse "Jobs retrieved"
+// @Failure 403 {object} ErrorResponse "Error: Not the owner of the asset"
+// @Failure 404 {object} ErrorResponse "Error: Asset not found"
+// @Security BearerAuth
+// @Router /assets/{id}/jobs [get]
+func (server *Server) getAssetJobs(ctx *gin.Context) {
+	payload, err := getUserPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var param getAssetJobsParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	asset, err := server.store.GetAssetsById(ctx, uuid.MustParse(param.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("asset is not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if asset.Uid != payload.Uid {
+		user, err := server.store.GetUserById(ctx, payload.Uid)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if user.Role != roleAdmin {
+			ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you are not the owner of this asset")))
+			return
+		}
+	}
+
+	jobs, err := server.store.ListJobsByAsset(ctx, asset.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := getAssetJobsResponse{Message: "jobs retrieved", Jobs: []JobResponse{}}
+	for i := range jobs {
+		res.Jobs = append(res.Jobs, ReturnJobResponse(&jobs[i]))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
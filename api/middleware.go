@@ -1,11 +1,16 @@
 package api
 
 import (
+	"crypto/subtle"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
 	"github.com/segment3d-app/segment3d-be/token"
 )
 
@@ -13,6 +18,9 @@ const (
 	authorizationHeaderKey    = "authorization"
 	authorizationHeaderBearer = "Bearer"
 	authorizationPayloadKey   = "autorizationPayload"
+	authorizationHeaderWorker = "Worker"
+	authorizationWorkerKey    = "authorizationWorker"
+	roleAdmin                 = "admin"
 )
 
 func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
@@ -83,3 +91,141 @@ func optionalAuthMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+func workerAuthMiddleware(credentials map[string]string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+
+		if len(authorizationHeader) == 0 {
+			error := errors.New("authorization header is empty")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(error))
+			return
+		}
+
+		field := strings.Fields(authorizationHeader)
+		if len(field) < 2 {
+			error := errors.New("authorization header is not valid")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(error))
+			return
+		}
+
+		authorizationType := strings.ToLower(field[0])
+		if authorizationType != strings.ToLower(authorizationHeaderWorker) {
+			error := errors.New("authorization type is not valid")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(error))
+			return
+		}
+
+		workerID, found := verifyWorkerToken(credentials, field[1])
+		if !found {
+			error := errors.New("worker token is not valid")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(error))
+			return
+		}
+
+		ctx.Set(authorizationWorkerKey, workerID)
+		ctx.Next()
+	}
+}
+
+// adminMiddleware only lets users with the admin role through. It has to run
+// after authMiddleware.
+func adminMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload, err := getUserPayload(ctx)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		user, err := store.GetUserById(ctx, payload.Uid)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		if user.Role != roleAdmin {
+			error := errors.New("admin role is required")
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(error))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// tusMiddleware marks the responses of the tus endpoints with the protocol
+// version and turns away clients that speak another one. OPTIONS requests
+// need not name a version.
+func tusMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header(tusResumableHeader, tusVersion)
+
+		if ctx.Request.Method != http.MethodOptions && ctx.GetHeader(tusResumableHeader) != tusVersion {
+			ctx.Header(tusVersionHeader, tusVersion)
+			error := errors.New("tus version is not supported")
+			ctx.AbortWithStatusJSON(http.StatusPreconditionFailed, errorResponse(error))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// verifyWorkerToken compares the token against every configured worker in
+// constant time so response timing does not leak which prefix matched.
+func verifyWorkerToken(credentials map[string]string, token string) (string, bool) {
+	var workerID string
+	found := false
+
+	for id, expected := range credentials {
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1 {
+			workerID = id
+			found = true
+		}
+	}
+
+	return workerID, found
+}
+
+// accessTokenQueryKey is the query parameter browsers pass the access token in
+// where they can't set headers, such as on WebSocket requests.
+const accessTokenQueryKey = "access_token"
+
+// logFormatter writes the request log line in the format of gin's default
+// logger, with the access token in the query redacted so it doesn't end up in
+// the logs.
+func logFormatter(param gin.LogFormatterParams) string {
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		redactAccessToken(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// redactAccessToken replaces the access token in the query of the path.
+func redactAccessToken(path string) string {
+	base, rawQuery, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return base + "?REDACTED"
+	}
+	if !query.Has(accessTokenQueryKey) {
+		return path
+	}
+
+	query.Set(accessTokenQueryKey, "REDACTED")
+	return base + "?" + query.Encode()
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/segment3d-app/segment3d-be/storage"
+)
+
+// errInvalidAssetPath is returned when a photo directory or point cloud given
+// for a new asset is not one of the caller's files.
+var errInvalidAssetPath = errors.New("invalid asset path")
+
+// userStoragePrefix is the part of the storage a user may create assets from.
+func userStoragePrefix(uid uuid.UUID) string {
+	return fmt.Sprintf("/files/%s/", uid)
+}
+
+// storagePath turns a url stored on an asset into a path in the storage. Both
+// plain storage paths and urls on the storage server are accepted.
+func (server *Server) storagePath(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || len(u.RawQuery) > 0 || len(u.Fragment) > 0 {
+		return "", fmt.Errorf("%w: %q is not a storage path", errInvalidAssetPath, raw)
+	}
+
+	p := u.Path
+	if len(u.Scheme) > 0 || len(u.Host) > 0 {
+		base, err := url.Parse(server.config.StorageUrl)
+		if err != nil || u.Scheme != base.Scheme || u.Host != base.Host {
+			return "", fmt.Errorf("%w: %s is not on the storage server", errInvalidAssetPath, raw)
+		}
+
+		basePath := strings.TrimSuffix(base.Path, "/")
+		if !strings.HasPrefix(p, basePath+"/") {
+			return "", fmt.Errorf("%w: %s is not on the storage server", errInvalidAssetPath, raw)
+		}
+		p = strings.TrimPrefix(p, basePath)
+	}
+
+	// cleaning resolves any .. before the path is compared to a prefix
+	return path.Clean("/" + p), nil
+}
+
+// ownedStoragePath is storagePath for a url that has to be in the storage of
+// the user.
+func (server *Server) ownedStoragePath(uid uuid.UUID, raw string) (string, error) {
+	p, err := server.storagePath(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(p, userStoragePrefix(uid)) {
+		return "", fmt.Errorf("%w: %s is outside of your storage at %s", errInvalidAssetPath, raw, userStoragePrefix(uid))
+	}
+
+	return p, nil
+}
+
+func assetPathErrorStatus(err error) int {
+	if errors.Is(err, errInvalidAssetPath) {
+		return http.StatusUnprocessableEntity
+	}
+
+	return http.StatusInternalServerError
+}
+
+// checkPhotoDir makes sure the photo directory belongs to the user, exists
+// and holds photos, and returns its storage path.
+func (server *Server) checkPhotoDir(ctx context.Context, uid uuid.UUID, raw string) (string, error) {
+	dir, err := server.ownedStoragePath(uid, raw)
+	if err != nil {
+		return "", err
+	}
+
+	// without listings the storage can only tell whether the directory has a
+	// photo to make a thumbnail of
+	if !server.storage.Supports(storage.CapabilityList) {
+		_, err := server.storage.Thumbnail(ctx, dir)
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", fmt.Errorf("%w: photo directory %s does not exist or has no photos", errInvalidAssetPath, dir)
+		}
+		return dir, err
+	}
+
+	files, err := server.storage.List(ctx, dir)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", fmt.Errorf("%w: photo directory %s does not exist", errInvalidAssetPath, dir)
+		}
+		return "", err
+	}
+
+	for _, file := range files {
+		if !file.IsDir && len(uploadImageTypes[strings.ToLower(path.Ext(file.Path))]) > 0 {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: photo directory %s has no jpg or png photos", errInvalidAssetPath, dir)
+}
+
+// checkPointCloud makes sure the point cloud belongs to the user and is a ply
+// file, and returns its storage path.
+func (server *Server) checkPointCloud(ctx context.Context, uid uuid.UUID, raw string) (string, error) {
+	p, err := server.ownedStoragePath(uid, raw)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.ToLower(path.Ext(p)) != pointCloudExtension {
+		return "", fmt.Errorf("%w: point cloud %s is not a ply file", errInvalidAssetPath, p)
+	}
+
+	info, err := server.storage.Stat(ctx, p)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", fmt.Errorf("%w: point cloud %s does not exist", errInvalidAssetPath, p)
+		}
+		return "", err
+	}
+	if info.IsDir {
+		return "", fmt.Errorf("%w: point cloud %s is a directory", errInvalidAssetPath, p)
+	}
+
+	head, err := server.storage.Peek(ctx, p, sniffLen)
+	if err != nil {
+		return "", err
+	}
+	if !isPointCloud(head) {
+		return "", fmt.Errorf("%w: point cloud %s has no valid ply header", errInvalidAssetPath, p)
+	}
+
+	return p, nil
+}
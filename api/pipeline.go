@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+)
+
+// transitionAsset moves the asset to the given state and records the change in
+// the asset status history. The update only applies while the asset is still
+// in the state it was read in, so concurrent callbacks cannot both advance it.
+func (server *Server) transitionAsset(ctx context.Context, asset db.Assets, to pipeline.State) (db.Assets, error) {
+	return server.transitionAssetWithEvent(ctx, asset, to, nil)
+}
+
+// transitionAssetWithEvent is transitionAsset that also queues the event in
+// the outbox within the same transaction, so the event is published exactly
+// when the status change is committed.
+func (server *Server) transitionAssetWithEvent(ctx context.Context, asset db.Assets, to pipeline.State, event *db.CreateOutboxEventParams) (db.Assets, error) {
+	return server.applyTransition(ctx, asset, to, db.TransitionAssetTxParams{Event: event})
+}
+
+// applyTransition runs the transition with the extra work in arg, such as
+// closing the open jobs, queues the webhooks of the change with it and
+// notifies the listeners of the asset.
+func (server *Server) applyTransition(ctx context.Context, asset db.Assets, to pipeline.State, arg db.TransitionAssetTxParams) (db.Assets, error) {
+	from := pipeline.State(asset.Status)
+	if err := server.pipelines.For(asset.Type).Transition(from, to); err != nil {
+		return asset, err
+	}
+
+	arg.ID = asset.ID
+	arg.FromStatus = string(from)
+	arg.ToStatus = string(to)
+	arg.Notify = transitionWebhooks(from, to)
+	if stage, ok := pipeline.StageOf(to); ok {
+		arg.EnqueueStage = string(stage)
+	}
+
+	newAsset, err := server.store.TransitionAssetTx(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return asset, fmt.Errorf("%w: asset status changed from %q", pipeline.ErrIllegalTransition, from)
+		}
+		if errors.Is(err, db.ErrJobFinished) {
+			return asset, errStaleCallback
+		}
+		return asset, err
+	}
+
+	server.publishAssetEvent(assetEventStatus, newAsset.ID, AssetStatusEvent{From: string(from), To: string(to)})
+
+	return newAsset, nil
+}
+
+func transitionErrorStatus(err error) int {
+	if errors.Is(err, pipeline.ErrCancelled) {
+		return http.StatusGone
+	}
+
+	if errors.Is(err, pipeline.ErrIllegalTransition) {
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
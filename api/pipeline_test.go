@@ -0,0 +1,472 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/outbox"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+	"github.com/segment3d-app/segment3d-be/rabbitmq"
+	"github.com/segment3d-app/segment3d-be/storage"
+	"github.com/segment3d-app/segment3d-be/util"
+)
+
+// pipelineStore keeps the user, assets, jobs and outbox of one pipeline run in
+// memory. It implements only the part of db.Store the run goes through:
+//
+//   - GetUserById, GetSlug and ListWebhooksForEvent for creating the asset
+//     and queueing its webhooks
+//   - GetAssetsById, GetJobById, CreateAssetTx and TransitionAssetTx for
+//     running its stages
+//   - ClaimOutboxEvents, MarkOutboxEventSent and CountInFlightJobsByUser for
+//     the outbox relay
+//
+// Any other method goes to the nil embedded Store and panics with a nil
+// pointer dereference, whose stack names the method the run started calling
+// and that has to be added here.
+type pipelineStore struct {
+	db.Store
+
+	mu     sync.Mutex
+	user   db.Users
+	assets map[uuid.UUID]db.Assets
+	jobs   map[uuid.UUID]db.Jobs
+	outbox []db.Outbox
+	// webhookEvents lists the events the webhooks were looked up for.
+	webhookEvents []string
+}
+
+func newPipelineStore(user db.Users) *pipelineStore {
+	return &pipelineStore{
+		user:   user,
+		assets: make(map[uuid.UUID]db.Assets),
+		jobs:   make(map[uuid.UUID]db.Jobs),
+	}
+}
+
+func (store *pipelineStore) GetUserById(ctx context.Context, uid uuid.UUID) (db.Users, error) {
+	if uid != store.user.Uid {
+		return db.Users{}, sql.ErrNoRows
+	}
+
+	return store.user, nil
+}
+
+func (store *pipelineStore) GetSlug(ctx context.Context, slug string) ([]string, error) {
+	return nil, nil
+}
+
+// ListWebhooksForEvent is only called by the Notify hook of a transition, which
+// runs while the store is locked.
+func (store *pipelineStore) ListWebhooksForEvent(ctx context.Context, arg db.ListWebhooksForEventParams) ([]db.Webhooks, error) {
+	store.webhookEvents = append(store.webhookEvents, arg.Column2)
+	return nil, nil
+}
+
+func (store *pipelineStore) GetAssetsById(ctx context.Context, id uuid.UUID) (db.Assets, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	asset, ok := store.assets[id]
+	if !ok {
+		return db.Assets{}, sql.ErrNoRows
+	}
+
+	return asset, nil
+}
+
+func (store *pipelineStore) GetJobById(ctx context.Context, id uuid.UUID) (db.Jobs, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	job, ok := store.jobs[id]
+	if !ok {
+		return db.Jobs{}, sql.ErrNoRows
+	}
+
+	return job, nil
+}
+
+func (store *pipelineStore) CreateAssetTx(ctx context.Context, arg db.CreateAssetTxParams) (db.CreateAssetTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	asset := db.Assets{
+		ID:           uuid.New(),
+		Uid:          arg.Uid,
+		Title:        arg.Title,
+		Slug:         arg.Slug,
+		Type:         arg.Type,
+		ThumbnailUrl: arg.ThumbnailUrl,
+		PhotoDirUrl:  arg.PhotoDirUrl,
+		PclUrl:       arg.PclUrl,
+		IsPrivate:    arg.IsPrivate,
+		Status:       arg.CreateAssetParams.Status,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	store.assets[asset.ID] = asset
+
+	event, err := arg.Event(asset)
+	if err != nil {
+		return db.CreateAssetTxResult{}, err
+	}
+
+	asset, err = store.transition(db.TransitionAssetTxParams{
+		ID:           asset.ID,
+		FromStatus:   asset.Status,
+		ToStatus:     arg.Status,
+		Event:        &event,
+		EnqueueStage: arg.Stage,
+		JobID:        arg.JobID,
+		Notify:       arg.Notify,
+	})
+
+	return db.CreateAssetTxResult{Asset: asset}, err
+}
+
+func (store *pipelineStore) TransitionAssetTx(ctx context.Context, arg db.TransitionAssetTxParams) (db.Assets, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.transition(arg)
+}
+
+// transition applies the parts of TransitionAssetTx a successful run uses.
+func (store *pipelineStore) transition(arg db.TransitionAssetTxParams) (db.Assets, error) {
+	asset, ok := store.assets[arg.ID]
+	if !ok || asset.Status != arg.FromStatus {
+		return asset, sql.ErrNoRows
+	}
+	asset.Status = arg.ToStatus
+
+	if arg.Output != nil {
+		url := arg.Output.Url
+		switch pipeline.Output(arg.Output.Output) {
+		case pipeline.OutputPclColmapUrl:
+			asset.PclColmapUrl = url
+		case pipeline.OutputSplatUrl:
+			asset.SplatUrl = url
+		case pipeline.OutputSegmentedPclDirUrl:
+			asset.SegmentedPclDirUrl = url
+		case pipeline.OutputSegmentedSplatDirUrl:
+			asset.SegmentedSplatDirUrl = url
+		}
+	}
+
+	if arg.FinishJob != nil {
+		job, ok := store.jobs[arg.FinishJobID.UUID]
+		if !arg.FinishJobID.Valid {
+			job, ok = store.openJob(arg.ID, arg.FinishJob.Stage)
+		}
+		if arg.FinishJobID.Valid && (!ok || job.FinishedAt.Valid) {
+			return asset, db.ErrJobFinished
+		}
+		if ok {
+			job.Status = arg.FinishJob.Status
+			job.WorkerId = arg.FinishJob.WorkerId
+			job.ResultUrl = arg.FinishJob.ResultUrl
+			job.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			store.jobs[job.ID] = job
+		}
+	}
+
+	events := []db.CreateOutboxEventParams{}
+	if arg.Event != nil {
+		events = append(events, *arg.Event)
+	}
+	if arg.EventFor != nil {
+		event, err := arg.EventFor(asset)
+		if err != nil {
+			return asset, err
+		}
+		events = append(events, event)
+	}
+	for _, event := range events {
+		store.outbox = append(store.outbox, db.Outbox{
+			ID:       uuid.New(),
+			Queue:    event.Queue,
+			Payload:  event.Payload,
+			Exchange: event.Exchange,
+			Uid:      event.Uid,
+		})
+	}
+
+	if len(arg.EnqueueStage) > 0 {
+		jobID := arg.JobID
+		if jobID == uuid.Nil {
+			jobID = uuid.New()
+		}
+		store.jobs[jobID] = db.Jobs{
+			ID:         jobID,
+			AssetsId:   arg.ID,
+			Stage:      arg.EnqueueStage,
+			Attempt:    1,
+			Status:     "queued",
+			EnqueuedAt: time.Now(),
+		}
+	}
+
+	if arg.Notify != nil {
+		err := arg.Notify(context.Background(), store, asset)
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	store.assets[asset.ID] = asset
+
+	return asset, nil
+}
+
+func (store *pipelineStore) openJob(assetID uuid.UUID, stage string) (db.Jobs, bool) {
+	for _, job := range store.jobs {
+		if job.AssetsId == assetID && job.Stage == stage && !job.FinishedAt.Valid {
+			return job, true
+		}
+	}
+
+	return db.Jobs{}, false
+}
+
+func (store *pipelineStore) ClaimOutboxEvents(ctx context.Context, arg db.ClaimOutboxEventsParams) ([]db.Outbox, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	events := []db.Outbox{}
+	for _, event := range store.outbox {
+		if !event.SentAt.Valid {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (store *pipelineStore) MarkOutboxEventSent(ctx context.Context, id uuid.UUID) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for i := range store.outbox {
+		if store.outbox[i].ID == id {
+			store.outbox[i].SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	}
+
+	return nil
+}
+
+func (store *pipelineStore) CountInFlightJobsByUser(ctx context.Context, uid uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+// stageMessage is the part of a stage event the workers report back with.
+type stageMessage struct {
+	AssetID  string `json:"asset_id"`
+	JobID    string `json:"job_id"`
+	SplatUrl string `json:"splat_url"`
+}
+
+func TestPipelineRunsEveryStage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	user := db.Users{Uid: uuid.New(), Email: "user@segment3d.app", Role: "user"}
+	store := newPipelineStore(user)
+	broker := rabbitmq.NewMemoryBroker()
+
+	// upload: the photos are in the storage of the user
+	root := t.TempDir()
+	photoDir := filepath.Join(root, user.Uid.String(), "photos")
+	if err := os.MkdirAll(photoDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(photoDir, "0001.jpg"), []byte{0xff, 0xd8, 0xff}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	local, err := storage.NewLocalStorage(root, "http://storage.test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pipelines, err := pipeline.LoadDefinitions("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := util.Config{TokenSymmetricKey: "12345678901234567890123456789012"}
+	server, err := NewServer(&config, store, broker, pipelines, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.ConsumePipelineResults(); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.ConsumeQueryResults(); err != nil {
+		t.Fatal(err)
+	}
+
+	accessToken, err := server.tokenMaker.CreateToken(user.Uid, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"title":       "Scene",
+		"isPrivate":   false,
+		"photoDirUrl": userStoragePrefix(user.Uid) + "photos",
+		"type":        "non_lidar",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/assets", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("create asset: got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var created CreateAssetsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	assetID := uuid.MustParse(created.Asset.ID)
+
+	relay := outbox.NewRelay(store, broker, outbox.Schedule{})
+	relayOutbox := func() {
+		// the relay publishes what is pending once before it looks at the
+		// context
+		done, cancel := context.WithCancel(ctx)
+		cancel()
+		relay.Run(done)
+	}
+
+	// the backend publishes every stage to its queue once the previous one
+	// finished, and the worker reports the result against the job of the
+	// message
+	outputs := make(map[pipeline.Stage]string)
+	for _, stage := range pipeline.Stages {
+		relayOutbox()
+
+		published := broker.Published(stage.Queue())
+		if len(published) != 1 {
+			t.Fatalf("%s: got %d messages on %s, want 1", stage, len(published), stage.Queue())
+		}
+
+		var msg stageMessage
+		if err := json.Unmarshal(published[0].Body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.AssetID != assetID.String() || len(msg.JobID) == 0 {
+			t.Fatalf("%s: got message for asset %q with job %q", stage, msg.AssetID, msg.JobID)
+		}
+		if (stage == pipeline.StagePTv3 || stage == pipeline.StageSaga) && msg.SplatUrl != outputs[pipeline.StageSplat] {
+			t.Fatalf("%s: got splat %q, want %q", stage, msg.SplatUrl, outputs[pipeline.StageSplat])
+		}
+
+		outputs[stage] = "/files/" + assetID.String() + "/" + string(stage)
+		body, _ := json.Marshal(PipelineResult{
+			Type:     string(stage),
+			AssetID:  assetID.String(),
+			WorkerID: "worker-1",
+			JobID:    msg.JobID,
+			URL:      outputs[stage],
+		})
+		if err := broker.Deliver(ctx, pipeline.ResultsQueue, body); err != nil {
+			t.Fatalf("%s: can't deliver result: %v", stage, err)
+		}
+	}
+
+	// nothing is published once the last stage finished
+	relayOutbox()
+	for _, stage := range pipeline.Stages {
+		if published := broker.Published(stage.Queue()); len(published) != 1 {
+			t.Fatalf("%s: got %d messages on %s after the run, want 1", stage, len(published), stage.Queue())
+		}
+	}
+
+	asset, err := store.GetAssetsById(ctx, assetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.Status != string(pipeline.StateCompleted) {
+		t.Fatalf("got status %q, want %q", asset.Status, pipeline.StateCompleted)
+	}
+
+	urls := map[pipeline.Stage]sql.NullString{
+		pipeline.StageColmap: asset.PclColmapUrl,
+		pipeline.StageSplat:  asset.SplatUrl,
+		pipeline.StagePTv3:   asset.SegmentedPclDirUrl,
+		pipeline.StageSaga:   asset.SegmentedSplatDirUrl,
+	}
+	for stage, url := range urls {
+		if url.String != outputs[stage] {
+			t.Errorf("%s: got url %q, want %q", stage, url.String, outputs[stage])
+		}
+	}
+
+	if len(store.jobs) != len(pipeline.Stages) {
+		t.Errorf("got %d jobs, want one per stage", len(store.jobs))
+	}
+	for _, job := range store.jobs {
+		if job.Status != jobStatusSucceeded {
+			t.Errorf("%s: got job status %q, want %q", job.Stage, job.Status, jobStatusSucceeded)
+		}
+	}
+
+	// every status change queued its webhooks along with it
+	webhookEvents := map[string]int{}
+	for _, event := range store.webhookEvents {
+		webhookEvents[event]++
+	}
+	if got, want := webhookEvents[webhookEventStatusChanged], len(pipeline.Stages)+1; got != want {
+		t.Errorf("got %d %s webhook lookups, want %d", got, webhookEventStatusChanged, want)
+	}
+	if got := webhookEvents[webhookEventCompleted]; got != 1 {
+		t.Errorf("got %d %s webhook lookups, want 1", got, webhookEventCompleted)
+	}
+
+	if cancels := broker.Broadcasted(pipeline.ControlExchange); len(cancels) != 0 {
+		t.Errorf("got %d cancel messages, want none", len(cancels))
+	}
+
+	// a segmentation query on the finished asset reaches the viewer through
+	// the broadcast every replica consumes
+	events, unsubscribe := server.hub.Subscribe(assetID)
+	defer unsubscribe()
+
+	queryResult, _ := json.Marshal(QueryResult{
+		AssetID:          assetID.String(),
+		UniqueIdentifier: "click-1",
+		URL:              "/files/" + assetID.String() + "/click-1.ply",
+	})
+	if err := broker.Deliver(ctx, queryResultsQueue, queryResult); err != nil {
+		t.Fatalf("can't deliver query result: %v", err)
+	}
+
+	if broadcast := broker.Broadcasted(queryResultsExchange); len(broadcast) != 1 {
+		t.Fatalf("got %d broadcast query results, want 1", len(broadcast))
+	}
+
+	select {
+	case event := <-events:
+		result, ok := event.Data.(SegmentationResultEvent)
+		if event.Type != assetEventQuery || !ok || result.UniqueIdentifier != "click-1" {
+			t.Fatalf("got event %+v, want the query result", event)
+		}
+	default:
+		t.Fatal("query result was not published to the sessions")
+	}
+}
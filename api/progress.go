@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+)
+
+const (
+	pipelineResultProgress = "progress"
+
+	// assetEventProgress is sent whenever a worker reports progress.
+	assetEventProgress = "progress"
+)
+
+// AssetProgressResponse is the latest progress a worker reported for the stage
+// the asset is in. Iteration, EtaSeconds and PreviewUrl are only set when the
+// worker sent them.
+type AssetProgressResponse struct {
+	Stage      string    `json:"stage"`
+	Percent    float64   `json:"percent"`
+	Iteration  *int32    `json:"iteration"`
+	EtaSeconds *int32    `json:"etaSeconds"`
+	PreviewUrl string    `json:"previewUrl"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// ReturnAssetProgressResponse returns nil when no progress was reported since
+// the asset last changed status.
+func ReturnAssetProgressResponse(asset *db.Assets) *AssetProgressResponse {
+	if !asset.ProgressStage.Valid {
+		return nil
+	}
+
+	res := &AssetProgressResponse{
+		Stage:      asset.ProgressStage.String,
+		Percent:    asset.ProgressPercent.Float64,
+		PreviewUrl: asset.ProgressPreviewUrl.String,
+		UpdatedAt:  asset.ProgressUpdatedAt.Time,
+	}
+	if asset.ProgressIteration.Valid {
+		res.Iteration = &asset.ProgressIteration.Int32
+	}
+	if asset.ProgressEtaSeconds.Valid {
+		res.EtaSeconds = &asset.ProgressEtaSeconds.Int32
+	}
+
+	return res
+}
+
+// stageProgress is a progress report of a worker, from either delivery path.
+type stageProgress struct {
+	Percent    float64
+	Iteration  *int32
+	EtaSeconds *int32
+	PreviewUrl string
+}
+
+// applyStageProgress stores the latest progress of the running stage and
+// streams it to the subscribers of the asset. Reports that arrive after the
+// asset left the stage are ignored.
+func (server *Server) applyStageProgress(ctx context.Context, assetID uuid.UUID, stage pipeline.Stage, progress stageProgress, delivery callbackDelivery) (db.Assets, error) {
+	asset, err := server.store.GetAssetsById(ctx, assetID)
+	if err != nil {
+		return asset, err
+	}
+
+	if pipeline.State(asset.Status) == pipeline.StateCancelled {
+		return asset, pipeline.ErrCancelled
+	}
+
+	if progress.Percent < 0 || progress.Percent > 100 {
+		return asset, fmt.Errorf("progress of %s must be between 0 and 100, not %v", stage, progress.Percent)
+	}
+
+	// progress is overwritten by every report, so only the job is checked
+	delivery.IdempotencyKey = ""
+	_, err = server.claimDelivery(ctx, asset, stage, delivery)
+	if err != nil {
+		return asset, err
+	}
+
+	arg := db.UpdateAssetProgressParams{
+		ID:                 asset.ID,
+		ProgressStage:      sql.NullString{String: string(stage), Valid: true},
+		ProgressPercent:    sql.NullFloat64{Float64: progress.Percent, Valid: true},
+		ProgressPreviewUrl: sql.NullString{String: progress.PreviewUrl, Valid: len(progress.PreviewUrl) > 0},
+		Status:             string(stage.State()),
+	}
+	if progress.Iteration != nil {
+		arg.ProgressIteration = sql.NullInt32{Int32: *progress.Iteration, Valid: true}
+	}
+	if progress.EtaSeconds != nil {
+		arg.ProgressEtaSeconds = sql.NullInt32{Int32: *progress.EtaSeconds, Valid: true}
+	}
+
+	updated, err := server.store.UpdateAssetProgress(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return asset, fmt.Errorf("%w: asset is %q, not running %s", errStaleCallback, asset.Status, stage)
+		}
+		return asset, err
+	}
+
+	server.publishAssetEvent(assetEventProgress, updated.ID, ReturnAssetProgressResponse(&updated))
+
+	return updated, nil
+}
+
+type ReportStageProgressRequest struct {
+	Stage      string  `json:"stage" binding:"required,oneof=colmap splat ptv3 saga"`
+	Percent    float64 `json:"percent" binding:"min=0,max=100"`
+	Iteration  *int32  `json:"iteration" binding:"omitempty,min=0"`
+	EtaSeconds *int32  `json:"etaSeconds" binding:"omitempty,min=0"`
+	PreviewUrl string  `json:"previewUrl"`
+	JobID      string  `json:"jobId" binding:"omitempty,uuid"`
+}
+
+type ReportStageProgressParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type ReportStageProgressResponse struct {
+	Message  string                 `json:"message"`
+	Progress *AssetProgressResponse `json:"progress"`
+}
+
+// ReportStageProgress stores how far a worker got with a stage
+// @Summary Report stage progress
+// @Description Called by a GPU worker while a stage runs, e.g. every few hundred splat training iterations. The latest report is stored on the asset until its status changes and is streamed to the event subscribers of the asset. Reports for a stage the asset already left are ignored.
+// @Tags assets
+// @Accept json
+// @Produce json
+// @Param   id   path   string     true  "Asset ID"
+// @Param   request  body   ReportStageProgressRequest     true  "Report Stage Progress Request"
+// @Success 200 {object} ReportStageProgressResponse "Progress recorded"
+// @Failure 404 {object} ErrorResponse "Error: Asset not found"
+// @Failure 409 {object} ErrorResponse "Error: Job does not belong to the asset"
+// @Failure 410 {object} ErrorResponse "Error: Asset was cancelled"
+// @Security WorkerAuth
+// @Router /assets/progress/{id} [patch]
+func (server *Server) reportStageProgress(ctx *gin.Context) {
+	var req ReportStageProgressRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var param ReportStageProgressParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	delivery, err := getCallbackDelivery(ctx, req.JobID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	progress := stageProgress{
+		Percent:    req.Percent,
+		Iteration:  req.Iteration,
+		EtaSeconds: req.EtaSeconds,
+		PreviewUrl: req.PreviewUrl,
+	}
+
+	asset, err := server.applyStageProgress(ctx, uuid.MustParse(param.ID), pipeline.Stage(req.Stage), progress, delivery)
+	if errors.Is(err, errIgnoredCallback) {
+		ctx.JSON(http.StatusOK, ReportStageProgressResponse{Message: err.Error(), Progress: ReturnAssetProgressResponse(&asset)})
+		return
+	}
+	if err != nil {
+		ctx.JSON(resultErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ReportStageProgressResponse{Message: "progress recorded", Progress: ReturnAssetProgressResponse(&asset)})
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+)
+
+type ReprocessAssetRequest struct {
+	FromStage string `json:"fromStage" binding:"omitempty,oneof=colmap splat ptv3 saga"`
+}
+
+type ReprocessAssetParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type ReprocessAssetResponse struct {
+	Message string        `json:"message"`
+	Asset   AssetResponse `json:"asset"`
+}
+
+type GeneratePTv3Event struct {
+	AssetID  string `json:"asset_id"`
+	SplatUrl string `json:"splat_url"`
+	Type     string `json:"type"`
+	JobID    string `json:"job_id"`
+}
+
+type GenerateSagaEvent struct {
+	AssetID            string `json:"asset_id"`
+	SplatUrl           string `json:"splat_url"`
+	SegmentedPclDirUrl string `json:"segmented_pcl_dir_url"`
+	Type               string `json:"type"`
+	JobID              string `json:"job_id"`
+}
+
+// ReprocessAsset re-runs the pipeline of an asset
+// @Summary Reprocess asset
+// @Description Re-runs the processing pipeline of a finished, failed or cancelled asset starting from the given stage (the first stage of its pipeline by default). Outputs of that stage and every later stage are cleared.
+// @Tags assets
+// @Accept json
+// @Produce json
+// @Param   id   path   string     true  "Asset ID"
+// @Param   request  body   ReprocessAssetRequest     false  "Reprocess Asset Request"
+// @Success 202 {object} ReprocessAssetResponse "Asset reprocessing started"
+// @Failure 403 {object} ErrorResponse "Error: Not the owner of the asset"
+// @Failure 409 {object} ErrorResponse "Error: Asset is already being processed"
+// @Security BearerAuth
+// @Router /assets/{id}/reprocess [post]
+func (server *Server) reprocessAsset(ctx *gin.Context) {
+	payload, err := getUserPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var param ReprocessAssetParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req ReprocessAssetRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	asset, err := server.store.GetAssetsById(ctx, uuid.MustParse(param.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if asset.Uid != payload.Uid {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("you are not the owner of this asset")))
+		return
+	}
+
+	if !pipeline.State(asset.Status).IsTerminal() {
+		ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("asset is already being processed (%s)", asset.Status)))
+		return
+	}
+
+	def := server.pipelines.For(asset.Type)
+	step := def.First()
+	if len(req.FromStage) > 0 {
+		var ok bool
+		step, ok = def.Step(pipeline.Stage(req.FromStage))
+		if !ok {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("assets of type %s do not run %s", asset.Type, req.FromStage)))
+			return
+		}
+	}
+	stage := step.Stage
+
+	if err := checkStageInputs(&asset, stage); err != nil {
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	jobID := uuid.New()
+	event, err := stageEvent(&asset, step, jobID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// the outputs are cleared, the jobs of the previous run closed and the
+	// stage queued in the transaction that moves the asset out of its
+	// terminal state. Only one request can do that, which keeps two runs of
+	// the same asset from starting at once, and late results of the previous
+	// run find their jobs closed.
+	asset, err = server.applyTransition(ctx, asset, stage.State(), db.TransitionAssetTxParams{
+		ResetOutputs: &db.ResetAssetOutputsParams{
+			ID:      asset.ID,
+			Column2: def.OutputsFrom(stage),
+		},
+		CloseJobs: &db.CloseOpenJobsParams{
+			AssetsId: asset.ID,
+			Status:   jobStatusCancelled,
+			Error:    sql.NullString{String: "superseded by a reprocess", Valid: true},
+		},
+		Event: &event,
+		JobID: jobID,
+	})
+	if err != nil {
+		ctx.JSON(transitionErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserById(ctx, asset.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := ReprocessAssetResponse{
+		Message: fmt.Sprintf("reprocessing asset from %s", stage),
+		Asset:   ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
+	}
+
+	ctx.JSON(http.StatusAccepted, res)
+}
+
+// checkStageInputs makes sure the outputs a stage consumes are still present.
+func checkStageInputs(asset *db.Assets, stage pipeline.Stage) error {
+	switch stage {
+	case pipeline.StageSplat:
+		if !asset.PclColmapUrl.Valid && !asset.PclUrl.Valid {
+			return fmt.Errorf("stage %s requires a point cloud, reprocess from colmap instead", stage)
+		}
+	case pipeline.StagePTv3, pipeline.StageSaga:
+		if !asset.SplatUrl.Valid {
+			return fmt.Errorf("stage %s requires a 3d splat, reprocess from splat instead", stage)
+		}
+	}
+
+	return nil
+}
+
+// stageEvent builds the outbox message that starts the step on the queue the
+// workers consume it from. It carries the owner of the asset so the relay can
+// limit the jobs each user has in flight, and the id of the job opened for the
+// stage so the worker can report against that exact attempt.
+func stageEvent(asset *db.Assets, step pipeline.Step, jobID uuid.UUID) (db.CreateOutboxEventParams, error) {
+	var event any
+	switch step.Stage {
+	case pipeline.StageSplat:
+		pclColmapUrl := asset.PclColmapUrl.String
+		if !asset.PclColmapUrl.Valid {
+			pclColmapUrl = asset.PclUrl.String
+		}
+		event = GenerateSplatEvent{
+			AssetID:      asset.ID.String(),
+			PhotoDirUrl:  asset.PhotoDirUrl,
+			PCLColmapUrl: pclColmapUrl,
+			Type:         asset.Type,
+			JobID:        jobID.String(),
+		}
+	case pipeline.StagePTv3:
+		event = GeneratePTv3Event{
+			AssetID:  asset.ID.String(),
+			SplatUrl: asset.SplatUrl.String,
+			Type:     asset.Type,
+			JobID:    jobID.String(),
+		}
+	case pipeline.StageSaga:
+		event = GenerateSagaEvent{
+			AssetID:            asset.ID.String(),
+			SplatUrl:           asset.SplatUrl.String,
+			SegmentedPclDirUrl: asset.SegmentedPclDirUrl.String,
+			Type:               asset.Type,
+			JobID:              jobID.String(),
+		}
+	default:
+		event = GenerateColmapEvent{
+			AssetID:       asset.ID.String(),
+			PhotoDirUrl:   asset.PhotoDirUrl,
+			Type:          asset.Type,
+			PointCloudUrl: asset.PclUrl.String,
+			JobID:         jobID.String(),
+		}
+	}
+
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return db.CreateOutboxEventParams{}, err
+	}
+
+	return db.CreateOutboxEventParams{
+		Queue:   step.Queue,
+		Payload: msg,
+		Uid:     uuid.NullUUID{UUID: asset.Uid, Valid: true},
+	}, nil
+}
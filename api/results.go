@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+)
+
+// PipelineResult is the message a worker publishes to the results queue. Type
+// is either a stage name, "started", "progress" or "failure". JobID and
+// IdempotencyKey are optional and make redelivered messages safe to apply.
+type PipelineResult struct {
+	Type           string `json:"type"`
+	AssetID        string `json:"asset_id"`
+	WorkerID       string `json:"worker_id"`
+	JobID          string `json:"job_id"`
+	IdempotencyKey string `json:"idempotency_key"`
+	URL            string `json:"url"`
+	Stage          string `json:"stage"`
+	Error          string `json:"error"`
+	LogsUrl        string `json:"logs_url"`
+	// Percent, Iteration, EtaSeconds and PreviewUrl are set on progress
+	// messages.
+	Percent    float64 `json:"percent"`
+	Iteration  *int32  `json:"iteration"`
+	EtaSeconds *int32  `json:"eta_seconds"`
+	PreviewUrl string  `json:"preview_url"`
+}
+
+const (
+	pipelineResultStarted = "started"
+	pipelineResultFailure = "failure"
+)
+
+// callbackEndpoints names the HTTP callback each stage result used to arrive
+// through, so both delivery paths are recorded the same way.
+var callbackEndpoints = map[pipeline.Stage]string{
+	pipeline.StageColmap: "pointcloud",
+	pipeline.StageSplat:  "gaussian",
+	pipeline.StagePTv3:   "ptv3",
+	pipeline.StageSaga:   "saga",
+}
+
+// ConsumePipelineResults starts handling results published by the workers.
+func (server *Server) ConsumePipelineResults() error {
+	return server.broker.Consume(pipeline.ResultsQueue, server.handlePipelineResult)
+}
+
+// handlePipelineResult applies a result from the results queue. Results for
+// cancelled assets are dropped instead of dead-lettered since the work was
+// stopped on purpose, and so are redelivered or stale results.
+func (server *Server) handlePipelineResult(ctx context.Context, body []byte) error {
+	err := server.applyPipelineResult(ctx, body)
+	if errors.Is(err, pipeline.ErrCancelled) {
+		log.Printf("dropping late pipeline result: %v", err)
+		return nil
+	}
+
+	if errors.Is(err, errIgnoredCallback) {
+		log.Printf("dropping pipeline result: %v", err)
+		return nil
+	}
+
+	return err
+}
+
+func (server *Server) applyPipelineResult(ctx context.Context, body []byte) error {
+	var result PipelineResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("can't decode pipeline result: %w", err)
+	}
+
+	assetID, err := uuid.Parse(result.AssetID)
+	if err != nil {
+		return fmt.Errorf("invalid asset id %q: %w", result.AssetID, err)
+	}
+
+	if len(result.WorkerID) == 0 {
+		return fmt.Errorf("pipeline result for asset %s has no worker id", assetID)
+	}
+
+	delivery, err := newCallbackDelivery(result.WorkerID, result.JobID, result.IdempotencyKey)
+	if err != nil {
+		return err
+	}
+
+	if result.Type == pipelineResultStarted {
+		stage, err := pipeline.ParseStage(result.Stage)
+		if err != nil {
+			return err
+		}
+
+		_, err = server.applyStageStart(ctx, assetID, stage, delivery)
+		return err
+	}
+
+	if result.Type == pipelineResultProgress {
+		stage, err := pipeline.ParseStage(result.Stage)
+		if err != nil {
+			return err
+		}
+
+		progress := stageProgress{
+			Percent:    result.Percent,
+			Iteration:  result.Iteration,
+			EtaSeconds: result.EtaSeconds,
+			PreviewUrl: result.PreviewUrl,
+		}
+		_, err = server.applyStageProgress(ctx, assetID, stage, progress, delivery)
+		return err
+	}
+
+	if result.Type == pipelineResultFailure {
+		stage, err := pipeline.ParseStage(result.Stage)
+		if err != nil {
+			return err
+		}
+
+		_, err = server.applyStageFailure(ctx, assetID, stage, result.Error, result.LogsUrl, delivery)
+		return err
+	}
+
+	stage, err := pipeline.ParseStage(result.Type)
+	if err != nil {
+		return err
+	}
+
+	if len(result.URL) == 0 {
+		return fmt.Errorf("%s result for asset %s has no url", stage, assetID)
+	}
+
+	_, err = server.applyStageResult(ctx, assetID, stage, result.URL, delivery)
+	return err
+}
+
+// applyStageResult stores the output url of a finished stage and advances the
+// asset to the next state. It backs both the HTTP callbacks and the results
+// queue.
+func (server *Server) applyStageResult(ctx context.Context, assetID uuid.UUID, stage pipeline.Stage, url string, delivery callbackDelivery) (asset db.Assets, err error) {
+	asset, err = server.store.GetAssetsById(ctx, assetID)
+	if err != nil {
+		return asset, err
+	}
+
+	def := server.pipelines.For(asset.Type)
+	step, ok := def.Step(stage)
+	if !ok {
+		return asset, fmt.Errorf("%w: assets of type %s do not run %s", pipeline.ErrIllegalTransition, asset.Type, stage)
+	}
+
+	if pipeline.State(asset.Status) == pipeline.StateCancelled {
+		return asset, pipeline.ErrCancelled
+	}
+
+	_, err = server.claimDelivery(ctx, asset, stage, delivery)
+	if err != nil {
+		return asset, err
+	}
+	defer func() {
+		if err != nil {
+			server.releaseDelivery(ctx, delivery)
+		}
+	}()
+
+	// the output, the worker and the finished job are stored in the same
+	// transaction as the status, so a result racing a cancel or a second
+	// delivery of itself either lands completely or not at all
+	arg := db.TransitionAssetTxParams{
+		Output: &db.SetAssetOutputParams{
+			ID:     assetID,
+			Output: string(step.Output),
+			Url:    sql.NullString{String: url, Valid: true},
+		},
+		WorkerCallback: workerCallbackParams(assetID, delivery.WorkerID, callbackEndpoints[stage], url),
+	}
+	arg.FinishJob, arg.FinishJobID = finishJobParams(assetID, stage, jobStatusSucceeded, delivery, url, "")
+
+	// the next stage is published through the outbox like the first one, so
+	// it runs on the queue of its step and the worker reports against its job
+	next := def.Next(stage)
+	if nextStage, ok := pipeline.StageOf(next); ok {
+		nextStep, _ := def.Step(nextStage)
+		jobID := uuid.New()
+		arg.JobID = jobID
+		arg.EventFor = func(asset db.Assets) (db.CreateOutboxEventParams, error) {
+			return stageEvent(&asset, nextStep, jobID)
+		}
+	}
+
+	asset, err = server.applyTransition(ctx, asset, next, arg)
+	if err != nil {
+		return asset, err
+	}
+
+	server.publishStageOutput(asset, stage, url)
+
+	return asset, nil
+}
+
+// applyStageFailure records a crash reported by a worker and fails the asset.
+func (server *Server) applyStageFailure(ctx context.Context, assetID uuid.UUID, stage pipeline.Stage, reason string, logsUrl string, delivery callbackDelivery) (asset db.Assets, err error) {
+	asset, err = server.store.GetAssetsById(ctx, assetID)
+	if err != nil {
+		return asset, err
+	}
+
+	if pipeline.State(asset.Status) == pipeline.StateCancelled {
+		return asset, pipeline.ErrCancelled
+	}
+
+	_, err = server.claimDelivery(ctx, asset, stage, delivery)
+	if err != nil {
+		return asset, err
+	}
+	defer func() {
+		if err != nil {
+			server.releaseDelivery(ctx, delivery)
+		}
+	}()
+
+	arg := db.TransitionAssetTxParams{
+		WorkerCallback: workerCallbackParams(assetID, delivery.WorkerID, pipelineResultFailure, logsUrl),
+	}
+	arg.FinishJob, arg.FinishJobID = finishJobParams(assetID, stage, jobStatusFailed, delivery, logsUrl, reason)
+
+	return server.markAssetFailed(ctx, asset, stage, reason, logsUrl, arg)
+}
+
+func resultErrorStatus(err error) int {
+	if errors.Is(err, sql.ErrNoRows) {
+		return http.StatusNotFound
+	}
+
+	return transitionErrorStatus(err)
+}
@@ -0,0 +1,371 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/segment3d-app/segment3d-be/hub"
+	"github.com/segment3d-app/segment3d-be/util"
+)
+
+const (
+	// queryResultsQueue is where workers publish the results of segmentation
+	// queries, correlated through the unique identifier of the query.
+	queryResultsQueue = "query.results"
+	// queryResultsExchange fans the query results out to every replica of the
+	// server, since the session waiting for a result may be on any of them.
+	queryResultsExchange = "query.results.sessions"
+
+	// assetEventQuery is sent when a segmentation query finished.
+	assetEventQuery = "query"
+
+	segmentationPrompt   = "prompt"
+	segmentationAccepted = "accepted"
+	segmentationResult   = "result"
+	segmentationError    = "error"
+	// segmentationResync ends a session that fell behind and may have missed
+	// the results of its prompts. The viewer reconnects and sends the prompts
+	// it is still waiting for again.
+	segmentationResync = "resync"
+
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+)
+
+// newUpgrader accepts WebSocket requests from the frontend origins and from
+// the origin of the backend itself. Requests without an Origin header don't
+// come from a browser and are accepted as well, they have to present an access
+// token like any other.
+func newUpgrader(origins map[string]bool) websocket.Upgrader {
+	return websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if len(origin) == 0 {
+				return true
+			}
+
+			normalized, ok := util.NormalizeOrigin(origin)
+			if !ok {
+				return false
+			}
+
+			host := strings.ToLower(r.Host)
+			return origins[normalized] || normalized == "http://"+host || normalized == "https://"+host
+		},
+	}
+}
+
+// QueryResult is the message a worker publishes to the query results queue.
+type QueryResult struct {
+	AssetID          string `json:"asset_id"`
+	UniqueIdentifier string `json:"unique_identifier"`
+	URL              string `json:"url"`
+	Error            string `json:"error"`
+}
+
+type SegmentationResultEvent struct {
+	UniqueIdentifier string `json:"uniqueIdentifier"`
+	Url              string `json:"url,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// SegmentationPrompt is a click prompt sent by the viewer.
+type SegmentationPrompt struct {
+	Type             string `json:"type"`
+	X                int    `json:"x"`
+	Y                int    `json:"y"`
+	URL              string `json:"url"`
+	UniqueIdentifier string `json:"uniqueIdentifier"`
+}
+
+// SegmentationReply is pushed to the viewer when a prompt is accepted and when
+// its result is ready or failed.
+type SegmentationReply struct {
+	Type             string `json:"type"`
+	UniqueIdentifier string `json:"uniqueIdentifier,omitempty"`
+	Url              string `json:"url,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// ConsumeQueryResults forwards the segmentation results published by the
+// workers to the sessions waiting for them. Only one replica of the server
+// takes each result from the queue, so it broadcasts the result to all of
+// them, and each one hands it to the sessions it holds.
+func (server *Server) ConsumeQueryResults() error {
+	err := server.broker.Consume(queryResultsQueue, server.broadcastQueryResult)
+	if err != nil {
+		return err
+	}
+
+	return server.broker.ConsumeBroadcast(queryResultsExchange, server.handleQueryResult)
+}
+
+// broadcastQueryResult passes a result taken from the queue on to every
+// replica. Malformed results are rejected here, so they are dead-lettered.
+func (server *Server) broadcastQueryResult(ctx context.Context, body []byte) error {
+	_, _, err := parseQueryResult(body)
+	if err != nil {
+		return err
+	}
+
+	return server.broker.Broadcast(queryResultsExchange, body)
+}
+
+func parseQueryResult(body []byte) (QueryResult, uuid.UUID, error) {
+	var result QueryResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return result, uuid.Nil, fmt.Errorf("can't decode query result: %w", err)
+	}
+
+	assetID, err := uuid.Parse(result.AssetID)
+	if err != nil {
+		return result, uuid.Nil, fmt.Errorf("invalid asset id %q: %w", result.AssetID, err)
+	}
+
+	if len(result.UniqueIdentifier) == 0 {
+		return result, uuid.Nil, fmt.Errorf("query result for asset %s has no unique identifier", assetID)
+	}
+
+	return result, assetID, nil
+}
+
+func (server *Server) handleQueryResult(ctx context.Context, body []byte) error {
+	result, assetID, err := parseQueryResult(body)
+	if err != nil {
+		return err
+	}
+
+	server.publishAssetEvent(assetEventQuery, assetID, SegmentationResultEvent{
+		UniqueIdentifier: result.UniqueIdentifier,
+		Url:              result.URL,
+		Error:            result.Error,
+	})
+
+	return nil
+}
+
+type segmentationSessionParam struct {
+	// the wildcard has to share its name with GET /api/assets/:slug
+	ID string `uri:"slug" binding:"required,uuid"`
+}
+
+// SegmentationSession opens an interactive SAGA segmentation session
+// @Summary SAGA segmentation session
+// @Description Upgrades to a WebSocket on which the viewer sends click prompts ({"type":"prompt","x":0,"y":0,"url":"","uniqueIdentifier":""}) and receives "accepted", "result" and "error" messages for each of them. A session that falls behind receives a "resync" message and is closed, so the viewer reconnects and sends its pending prompts again. Browsers may pass the access token as the access_token query parameter.
+// @Tags assets
+// @Param   id   path   string  true  "Asset ID"
+// @Param   access_token   query   string  false  "Access token"
+// @Success 101 {object} SegmentationReply "Switching protocols"
+// @Failure 401 {object} ErrorResponse "Error: Not authenticated"
+// @Failure 404 {object} ErrorResponse "Error: Asset not found"
+// @Security BearerAuth
+// @Router /assets/{id}/segment/ws [get]
+func (server *Server) segmentationSession(ctx *gin.Context) {
+	// browsers cannot set headers on WebSocket requests, the request log
+	// redacts the token, see logFormatter
+	payload, err := getUserPayload(ctx)
+	if err != nil {
+		payload, err = server.tokenMaker.VerifyToken(ctx.Query(accessTokenQueryKey))
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+	}
+
+	var param segmentationSessionParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	asset, err := server.store.GetAssetsById(ctx, uuid.MustParse(param.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("asset is not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if asset.IsPrivate && asset.Uid != payload.Uid {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("asset is not found")))
+		return
+	}
+
+	conn, err := server.upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// the upgrader already replied to the client
+		return
+	}
+	defer conn.Close()
+
+	session := &segmentationSession{
+		server:  server,
+		conn:    conn,
+		assetID: asset.ID,
+		pending: make(map[string]bool),
+		replies: make(chan SegmentationReply, 16),
+		done:    make(chan struct{}),
+	}
+	session.run()
+}
+
+// segmentationSession relays the prompts of one viewer to the workers and the
+// results back. All writes to the connection happen in writeLoop.
+type segmentationSession struct {
+	server  *Server
+	conn    *websocket.Conn
+	assetID uuid.UUID
+
+	mu      sync.Mutex
+	pending map[string]bool
+
+	replies chan SegmentationReply
+	done    chan struct{}
+}
+
+func (session *segmentationSession) run() {
+	events, unsubscribe := session.server.hub.Subscribe(session.assetID)
+	defer unsubscribe()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		session.writeLoop(events)
+	}()
+
+	session.readLoop()
+	close(session.done)
+	wg.Wait()
+}
+
+func (session *segmentationSession) readLoop() {
+	session.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	session.conn.SetPongHandler(func(string) error {
+		return session.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var prompt SegmentationPrompt
+		err := session.conn.ReadJSON(&prompt)
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("segmentation session of asset %s closed: %v", session.assetID, err)
+			}
+			return
+		}
+
+		reply := session.handlePrompt(prompt)
+		select {
+		case session.replies <- reply:
+		case <-time.After(wsWriteWait):
+			return
+		}
+	}
+}
+
+func (session *segmentationSession) handlePrompt(prompt SegmentationPrompt) SegmentationReply {
+	if prompt.Type != segmentationPrompt {
+		return SegmentationReply{Type: segmentationError, Error: fmt.Sprintf("unknown message type %q", prompt.Type)}
+	}
+	if len(prompt.URL) == 0 {
+		return SegmentationReply{Type: segmentationError, UniqueIdentifier: prompt.UniqueIdentifier, Error: "url is required"}
+	}
+	if len(prompt.UniqueIdentifier) == 0 {
+		prompt.UniqueIdentifier = uuid.NewString()
+	}
+
+	session.mu.Lock()
+	session.pending[prompt.UniqueIdentifier] = true
+	session.mu.Unlock()
+
+	err := publishSegmentUsingSagaEvent(session.server, GenerateSegmentUsingSagaEvent{
+		AssetID:          session.assetID.String(),
+		X:                prompt.X,
+		Y:                prompt.Y,
+		URL:              prompt.URL,
+		UniqueIdentifier: prompt.UniqueIdentifier,
+	})
+	if err != nil {
+		session.resolve(prompt.UniqueIdentifier)
+		return SegmentationReply{Type: segmentationError, UniqueIdentifier: prompt.UniqueIdentifier, Error: err.Error()}
+	}
+
+	return SegmentationReply{Type: segmentationAccepted, UniqueIdentifier: prompt.UniqueIdentifier}
+}
+
+// resolve reports whether the query was still pending in this session and
+// forgets it.
+func (session *segmentationSession) resolve(uniqueIdentifier string) bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if !session.pending[uniqueIdentifier] {
+		return false
+	}
+	delete(session.pending, uniqueIdentifier)
+
+	return true
+}
+
+func (session *segmentationSession) writeLoop(events <-chan hub.Event) {
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	// closing the connection also ends a readLoop blocked on the client
+	defer session.conn.Close()
+
+	for {
+		select {
+		case <-session.done:
+			return
+		case reply := <-session.replies:
+			if err := session.write(reply); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				// the hub dropped the subscription since the session fell
+				// behind
+				session.write(SegmentationReply{Type: segmentationResync})
+				return
+			}
+			result, ok := event.Data.(SegmentationResultEvent)
+			if event.Type != assetEventQuery || !ok || !session.resolve(result.UniqueIdentifier) {
+				continue
+			}
+
+			reply := SegmentationReply{Type: segmentationResult, UniqueIdentifier: result.UniqueIdentifier, Url: result.Url}
+			if len(result.Error) > 0 {
+				reply = SegmentationReply{Type: segmentationError, UniqueIdentifier: result.UniqueIdentifier, Error: result.Error}
+			}
+			if err := session.write(reply); err != nil {
+				return
+			}
+		case <-ping.C:
+			session.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := session.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (session *segmentationSession) write(reply SegmentationReply) error {
+	session.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return session.conn.WriteJSON(reply)
+}
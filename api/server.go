@@ -5,9 +5,13 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	db "github.com/segment3d-app/segment3d-be/db/sqlc"
 	"github.com/segment3d-app/segment3d-be/docs"
+	"github.com/segment3d-app/segment3d-be/hub"
+	"github.com/segment3d-app/segment3d-be/pipeline"
 	"github.com/segment3d-app/segment3d-be/rabbitmq"
+	"github.com/segment3d-app/segment3d-be/storage"
 	"github.com/segment3d-app/segment3d-be/token"
 	"github.com/segment3d-app/segment3d-be/util"
 	swaggerfiles "github.com/swaggo/files"
@@ -15,24 +19,64 @@ import (
 )
 
 type Server struct {
-	config     util.Config
-	store      db.Store
-	router     *gin.Engine
-	tokenMaker token.Maker
-	rabbitmq   rabbitmq.RabbitMq
+	config            util.Config
+	store             db.Store
+	router            *gin.Engine
+	tokenMaker        token.Maker
+	broker            rabbitmq.Broker
+	hub               *hub.Hub
+	workerCredentials map[string]string
+	watchdog          watchdogConfig
+	pipelines         pipeline.Definitions
+	storage           storage.Storage
+	upgrader          websocket.Upgrader
+}
+
+// queryQueue is where segmentation queries on finished assets are published.
+// Users wait on them interactively, so they get a queue of their own rather
+// than a priority: priorities only order the messages within one queue, and
+// a query behind a running reconstruction would wait for it to finish. The
+// workers give the queue capacity of its own, see README.md.
+const queryQueue = "query"
+
+// PublishedQueues lists the queues the server publishes to when running the
+// given pipelines.
+func PublishedQueues(pipelines pipeline.Definitions) []string {
+	return append([]string{queryQueue}, pipelines.Queues()...)
+}
+
+// ConsumedQueues returns the queues the server takes messages from and nacks
+// to their dead-letter queues when it fails to handle them.
+func ConsumedQueues() []string {
+	return []string{pipeline.ResultsQueue, queryResultsQueue}
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
-func NewServer(config *util.Config, store db.Store, rmq *rabbitmq.RabbitMq) (*Server, error) {
+func NewServer(config *util.Config, store db.Store, broker rabbitmq.Broker, pipelines pipeline.Definitions, storage storage.Storage) (*Server, error) {
 	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, err
 	}
 
-	server := &Server{config: *config, store: store, tokenMaker: tokenMaker, rabbitmq: *rmq}
+	workerCredentials, err := util.ParseWorkerTokens(config.WorkerTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	watchdog, err := newWatchdogConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	origins, err := util.ParseOrigins(config.FrontendOrigins)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &Server{config: *config, store: store, tokenMaker: tokenMaker, broker: broker, hub: hub.NewHub(), workerCredentials: workerCredentials, watchdog: watchdog, pipelines: pipelines, storage: storage, upgrader: newUpgrader(origins)}
 	server.setupRouter()
 
 	return server, nil
@@ -43,9 +87,14 @@ func (server *Server) Start(address string) error {
 }
 
 func (server *Server) setupRouter() {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.LoggerWithFormatter(logFormatter), gin.Recovery())
 	authenticatedRouter := router.Group("/").Use(authMiddleware(server.tokenMaker))
 	optionalAutenticatedRouter := router.Group("/").Use(optionalAuthMiddleware(server.tokenMaker))
+	workerRouter := router.Group("/").Use(workerAuthMiddleware(server.workerCredentials))
+	adminRouter := router.Group("/").Use(authMiddleware(server.tokenMaker), adminMiddleware(server.store))
+	tusRouter := router.Group("/").Use(tusMiddleware())
+	authenticatedTusRouter := router.Group("/").Use(tusMiddleware(), authMiddleware(server.tokenMaker))
 
 	// configure swagger docs
 	docs.SwaggerInfo.BasePath = "/api"
@@ -73,14 +122,50 @@ func (server *Server) setupRouter() {
 	authenticatedRouter.GET("/api/assets/:slug", server.getAssetDetails)
 	authenticatedRouter.GET("/api/assets/me", server.getMyAssets)
 	authenticatedRouter.DELETE("/api/assets/:id", server.removeAsset)
-	router.PATCH("/api/assets/pointcloud/:id", server.updatePointCloudUrl)
-	router.PATCH("/api/assets/gaussian/:id", server.updateGaussianUrl)
-	router.POST("/api/assets/saga/segment/:id", server.segmentUsingSaga)
-	router.PATCH("/api/assets/ptv3/:id", server.updatePTv3Url)
-	router.PATCH("/api/assets/saga/:id", server.updateSagaUrl)
+	authenticatedRouter.POST("/api/assets/:id/reprocess", server.reprocessAsset)
+	authenticatedRouter.POST("/api/assets/:id/cancel", server.cancelAsset)
+	optionalAutenticatedRouter.GET("/api/assets/:slug/events", server.streamAssetEvents)
+	optionalAutenticatedRouter.GET("/api/assets/:slug/segment/ws", server.segmentationSession)
+	workerRouter.PATCH("/api/assets/pointcloud/:id", server.updatePointCloudUrl)
+	workerRouter.PATCH("/api/assets/gaussian/:id", server.updateGaussianUrl)
+	authenticatedRouter.POST("/api/assets/saga/segment/:id", server.segmentUsingSaga)
+	workerRouter.PATCH("/api/assets/ptv3/:id", server.updatePTv3Url)
+	workerRouter.PATCH("/api/assets/saga/:id", server.updateSagaUrl)
+	workerRouter.PATCH("/api/assets/failure/:id", server.reportAssetFailure)
+	workerRouter.PATCH("/api/assets/start/:id", server.reportStageStart)
+	workerRouter.PATCH("/api/assets/progress/:id", server.reportStageProgress)
+	workerRouter.POST("/api/workers/register", server.registerWorker)
+	workerRouter.POST("/api/workers/heartbeat", server.heartbeatWorker)
+	authenticatedRouter.GET("/api/assets/:slug/jobs", server.getAssetJobs)
 	authenticatedRouter.POST("/api/assets/like/:id", server.likeAsset)
 	authenticatedRouter.POST("/api/assets/unlike/:id", server.unlikeAsset)
 
+	// upload api
+	authenticatedRouter.POST("/api/uploads", server.createUploadSession)
+	authenticatedRouter.GET("/api/uploads/:id", server.getUploadSession)
+	authenticatedRouter.PUT("/api/uploads/:id/files/:name", server.uploadChunk)
+	authenticatedRouter.POST("/api/uploads/:id/files", server.uploadFiles)
+	authenticatedRouter.POST("/api/uploads/:id/finalize", server.finalizeUploadSession)
+	tusRouter.OPTIONS("/api/uploads/:id/tus", server.tusOptions)
+	authenticatedTusRouter.POST("/api/uploads/:id/tus", server.tusCreate)
+	authenticatedTusRouter.HEAD("/api/uploads/:id/tus/:name", server.tusOffset)
+	authenticatedTusRouter.PATCH("/api/uploads/:id/tus/:name", server.tusAppend)
+	authenticatedTusRouter.DELETE("/api/uploads/:id/tus/:name", server.tusTerminate)
+
+	// webhook api
+	authenticatedRouter.POST("/api/webhooks", server.createWebhook)
+	authenticatedRouter.GET("/api/webhooks", server.getWebhooks)
+	authenticatedRouter.PATCH("/api/webhooks/:id", server.updateWebhook)
+	authenticatedRouter.DELETE("/api/webhooks/:id", server.deleteWebhook)
+	authenticatedRouter.GET("/api/webhooks/:id/deliveries", server.getWebhookDeliveries)
+
+	// admin api
+	adminRouter.GET("/api/admin/dead-letters", server.listDeadLetters)
+	adminRouter.POST("/api/admin/dead-letters/:id/replay", server.replayDeadLetter)
+	adminRouter.DELETE("/api/admin/dead-letters/:id", server.discardDeadLetter)
+	adminRouter.GET("/api/admin/workers", server.listWorkers)
+	adminRouter.GET("/api/admin/cleanup-jobs", server.listCleanupJobs)
+
 	// tag api
 	router.GET("/api/tags/search", server.GetTagBySearchKeyword)
 
@@ -100,6 +185,19 @@ func getUserPayload(ctx *gin.Context) (*token.Payload, error) {
 	return userPayload, nil
 }
 
+func getWorkerID(ctx *gin.Context) (string, error) {
+	workerID, exists := ctx.Get(authorizationWorkerKey)
+	if !exists {
+		return "", fmt.Errorf("worker id is missing")
+	}
+	id, ok := workerID.(string)
+	if !ok {
+		return "", fmt.Errorf("worker id structure is not correct")
+	}
+
+	return id, nil
+}
+
 func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
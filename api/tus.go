@@ -0,0 +1,322 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+)
+
+// The tus 1.0 resumable upload protocol, see https://tus.io/protocols/resumable-upload.
+// Every upload of the protocol is a file of an upload session, so the files
+// uploaded over tus end up in the same asset as the ones sent over the plain
+// upload endpoints.
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination"
+	tusFileKey    = "filename"
+
+	tusResumableHeader = "Tus-Resumable"
+	tusVersionHeader   = "Tus-Version"
+	tusExtensionHeader = "Tus-Extension"
+	tusMaxSizeHeader   = "Tus-Max-Size"
+	uploadOffsetHeader = "Upload-Offset"
+	uploadLengthHeader = "Upload-Length"
+	uploadMetaHeader   = "Upload-Metadata"
+	uploadDeferHeader  = "Upload-Defer-Length"
+
+	tusContentType = "application/offset+octet-stream"
+)
+
+// tusLocation is the url of the tus upload of a file in the session.
+func tusLocation(session db.UploadSessions, name string) string {
+	return fmt.Sprintf("/api/uploads/%s/tus/%s", session.ID, name)
+}
+
+// parseTusMetadata decodes the Upload-Metadata header, a comma separated list
+// of keys each followed by an optional base64 encoded value.
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			metadata[fields[0]] = ""
+		case 2:
+			value, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("metadata %s is not base64 encoded", fields[0])
+			}
+			metadata[fields[0]] = string(value)
+		default:
+			return nil, fmt.Errorf("invalid upload metadata %q", pair)
+		}
+	}
+
+	return metadata, nil
+}
+
+// parseTusOffset reads a non-negative byte count from the header.
+func parseTusOffset(ctx *gin.Context, header string) (int64, error) {
+	value := ctx.GetHeader(header)
+	if len(value) == 0 {
+		return 0, fmt.Errorf("%s header is missing", header)
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s header is not a valid byte count", header)
+	}
+
+	return n, nil
+}
+
+// TusOptions describes the tus support of the server
+// @Summary Tus capabilities
+// @Description Lists the tus versions and extensions the upload endpoints support and the largest upload they accept.
+// @Tags uploads
+// @Param   id   path   string  true  "Upload Session ID"
+// @Success 204 "Tus capabilities in the response headers"
+// @Router /uploads/{id}/tus [options]
+func (server *Server) tusOptions(ctx *gin.Context) {
+	ctx.Header(tusVersionHeader, tusVersion)
+	ctx.Header(tusExtensionHeader, tusExtensions)
+	ctx.Header(tusMaxSizeHeader, strconv.FormatInt(server.uploadLimits().MaxBytes, 10))
+	ctx.Status(http.StatusNoContent)
+}
+
+// TusCreate starts the tus upload of a file
+// @Summary Create tus upload
+// @Description Creates a tus upload for a file of the upload session, named by the filename key of the Upload-Metadata header. Declared files must be created with their declared size; other files are added to the session if it stays within the upload limits. Creating a file that exists again returns its upload, so a client that lost the url can resume it.
+// @Tags uploads
+// @Param   id               path    string  true  "Upload Session ID"
+// @Param   Tus-Resumable    header  string  true  "Tus protocol version, 1.0.0"
+// @Param   Upload-Length    header  int     true  "Size of the file in bytes"
+// @Param   Upload-Metadata  header  string  true  "Tus metadata with the base64 encoded filename"
+// @Success 201 "Upload created, its url is in the Location header"
+// @Failure 400 {object} ErrorResponse "Error: Invalid length or metadata"
+// @Failure 404 {object} ErrorResponse "Error: Upload session not found"
+// @Failure 409 {object} ErrorResponse "Error: Size differs from the declared one or the session is finalized"
+// @Failure 412 {object} ErrorResponse "Error: Unsupported tus version"
+// @Failure 413 {object} ErrorResponse "Error: Upload exceeds the limits"
+// @Failure 415 {object} ErrorResponse "Error: Unsupported file type"
+// @Security BearerAuth
+// @Router /uploads/{id}/tus [post]
+func (server *Server) tusCreate(ctx *gin.Context) {
+	session, ok := server.bindUploadSession(ctx, true)
+	if !ok {
+		return
+	}
+
+	if len(ctx.GetHeader(uploadDeferHeader)) > 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("deferred upload length is not supported")))
+		return
+	}
+
+	length, err := parseTusOffset(ctx, uploadLengthHeader)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	limits := server.uploadLimits()
+	if length > limits.MaxBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(fmt.Errorf("%w: %d bytes exceed the limit of %d bytes", errUploadTooLarge, length, limits.MaxBytes)))
+		return
+	}
+
+	metadata, err := parseTusMetadata(ctx.GetHeader(uploadMetaHeader))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	name := metadata[tusFileKey]
+	if len(name) == 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("upload metadata has no %s", tusFileKey)))
+		return
+	}
+
+	file, err := server.store.GetUploadFile(ctx, db.GetUploadFileParams{UploadSessionsId: session.ID, Name: name})
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err == sql.ErrNoRows {
+		files, err := server.store.ListUploadFiles(ctx, session.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		_, _, err = limits.checkUploadSize(append(files, db.UploadFiles{Name: name, Size: length}))
+		if err != nil {
+			ctx.JSON(uploadCheckStatus(err), errorResponse(err))
+			return
+		}
+
+		file, err = server.store.CreateUploadFile(ctx, db.CreateUploadFileParams{
+			UploadSessionsId: session.ID,
+			Name:             name,
+			Size:             length,
+		})
+		if err != nil {
+			if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+				ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("file %s is being created already", name)))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	if file.Size != length {
+		ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("file %s was declared with %d bytes, not %d", name, file.Size, length)))
+		return
+	}
+
+	ctx.Header("Location", tusLocation(session, file.Name))
+	ctx.Status(http.StatusCreated)
+}
+
+type tusUploadParam struct {
+	Name string `uri:"name" binding:"required"`
+}
+
+// bindTusUpload loads the file of the session the tus upload url names. Like
+// bindUploadSession it writes the error response when that fails.
+func (server *Server) bindTusUpload(ctx *gin.Context, open bool) (db.UploadSessions, db.UploadFiles, bool) {
+	session, ok := server.bindUploadSession(ctx, open)
+	if !ok {
+		return session, db.UploadFiles{}, false
+	}
+
+	var param tusUploadParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return session, db.UploadFiles{}, false
+	}
+
+	file, err := server.store.GetUploadFile(ctx, db.GetUploadFileParams{UploadSessionsId: session.ID, Name: param.Name})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("file %s is not part of the upload", param.Name)))
+			return session, file, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return session, file, false
+	}
+
+	return session, file, true
+}
+
+// TusOffset tells where a tus upload has to resume
+// @Summary Get tus upload offset
+// @Description Returns the bytes of the file received so far in the Upload-Offset header, where the next PATCH has to start.
+// @Tags uploads
+// @Param   id             path    string  true  "Upload Session ID"
+// @Param   name           path    string  true  "File name"
+// @Param   Tus-Resumable  header  string  true  "Tus protocol version, 1.0.0"
+// @Success 200 "Offset in the Upload-Offset header"
+// @Failure 404 "Error: Upload not found"
+// @Failure 412 "Error: Unsupported tus version"
+// @Security BearerAuth
+// @Router /uploads/{id}/tus/{name} [head]
+func (server *Server) tusOffset(ctx *gin.Context) {
+	_, file, ok := server.bindTusUpload(ctx, false)
+	if !ok {
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header(uploadOffsetHeader, strconv.FormatInt(file.Received, 10))
+	ctx.Header(uploadLengthHeader, strconv.FormatInt(file.Size, 10))
+	ctx.Status(http.StatusOK)
+}
+
+// TusAppend stores the next part of a tus upload
+// @Summary Append to tus upload
+// @Description Streams the request body into the file from the offset in the Upload-Offset header, which must equal the bytes received so far. When the connection drops, what arrived is kept and the upload resumes from there.
+// @Tags uploads
+// @Accept application/offset+octet-stream
+// @Param   id             path    string  true  "Upload Session ID"
+// @Param   name           path    string  true  "File name"
+// @Param   Tus-Resumable  header  string  true  "Tus protocol version, 1.0.0"
+// @Param   Upload-Offset  header  int     true  "Offset of the data in the file"
+// @Success 204 "Data stored, the new offset is in the Upload-Offset header"
+// @Failure 400 {object} ErrorResponse "Error: Invalid offset"
+// @Failure 404 {object} ErrorResponse "Error: Upload not found"
+// @Failure 409 {object} ErrorResponse "Error: Offset does not match or the session is finalized"
+// @Failure 412 {object} ErrorResponse "Error: Unsupported tus version"
+// @Failure 413 {object} ErrorResponse "Error: File is larger than declared"
+// @Failure 415 {object} ErrorResponse "Error: Wrong content type or file type"
+// @Security BearerAuth
+// @Router /uploads/{id}/tus/{name} [patch]
+func (server *Server) tusAppend(ctx *gin.Context) {
+	if ctx.ContentType() != tusContentType {
+		ctx.JSON(http.StatusUnsupportedMediaType, errorResponse(fmt.Errorf("content type must be %s", tusContentType)))
+		return
+	}
+
+	offset, err := parseTusOffset(ctx, uploadOffsetHeader)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	session, file, ok := server.bindTusUpload(ctx, true)
+	if !ok {
+		return
+	}
+
+	file, err = server.writeUpload(ctx, session, file, offset, ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(uploadErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.Header(uploadOffsetHeader, strconv.FormatInt(file.Received, 10))
+	ctx.Status(http.StatusNoContent)
+}
+
+// TusTerminate removes a tus upload
+// @Summary Terminate tus upload
+// @Description Deletes the file and what was uploaded of it, and removes it from the upload session.
+// @Tags uploads
+// @Param   id             path    string  true  "Upload Session ID"
+// @Param   name           path    string  true  "File name"
+// @Param   Tus-Resumable  header  string  true  "Tus protocol version, 1.0.0"
+// @Success 204 "Upload terminated"
+// @Failure 404 {object} ErrorResponse "Error: Upload not found"
+// @Failure 409 {object} ErrorResponse "Error: Session is finalized"
+// @Failure 412 {object} ErrorResponse "Error: Unsupported tus version"
+// @Security BearerAuth
+// @Router /uploads/{id}/tus/{name} [delete]
+func (server *Server) tusTerminate(ctx *gin.Context) {
+	session, file, ok := server.bindTusUpload(ctx, true)
+	if !ok {
+		return
+	}
+
+	err := server.storage.Delete(ctx, uploadPath(session, file.Name))
+	if err != nil {
+		ctx.JSON(uploadErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	err = server.store.DeleteUploadFile(ctx, db.DeleteUploadFileParams{UploadSessionsId: session.ID, Name: file.Name})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,721 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+	"github.com/segment3d-app/segment3d-be/storage"
+)
+
+const (
+	uploadStatusOpen = "open"
+
+	defaultUploadMinImages = 3
+	defaultUploadMaxImages = 500
+	defaultUploadMaxBytes  = 2 << 30
+
+	// sniffLen is as much of a file as http.DetectContentType looks at.
+	sniffLen = 512
+	// pointCloudExtension is the one file type besides photos an upload may
+	// carry. It becomes the point cloud of the asset.
+	pointCloudExtension = ".ply"
+)
+
+// uploadImageTypes maps the photo extensions to their sniffed content type.
+var uploadImageTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+}
+
+// plyFormats are the encodings a ply header may declare.
+var plyFormats = map[string]bool{
+	"ascii":                true,
+	"binary_little_endian": true,
+	"binary_big_endian":    true,
+}
+
+var uploadFileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,254}$`)
+
+var (
+	errUploadOffset   = errors.New("upload offset does not match")
+	errUploadTooLarge = errors.New("upload is too large")
+	errUploadType     = errors.New("unsupported file type")
+)
+
+// uploadLimits bounds what a single upload session may carry.
+type uploadLimits struct {
+	MinImages int
+	MaxImages int
+	MaxBytes  int64
+}
+
+func (server *Server) uploadLimits() uploadLimits {
+	limits := uploadLimits{
+		MinImages: server.config.UploadMinImages,
+		MaxImages: server.config.UploadMaxImages,
+		MaxBytes:  server.config.UploadMaxBytes,
+	}
+	if limits.MinImages <= 0 {
+		limits.MinImages = defaultUploadMinImages
+	}
+	if limits.MaxImages <= 0 {
+		limits.MaxImages = defaultUploadMaxImages
+	}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = defaultUploadMaxBytes
+	}
+
+	return limits
+}
+
+// checkUploadFiles validates the names, types and sizes of the files of an
+// upload against the limits and returns the point cloud among them, if any.
+func (limits uploadLimits) checkUploadFiles(files []db.UploadFiles) (string, error) {
+	pointCloud, images, err := limits.checkUploadSize(files)
+	if err != nil {
+		return "", err
+	}
+
+	if images < limits.MinImages {
+		return "", fmt.Errorf("an upload needs between %d and %d photos, not %d", limits.MinImages, limits.MaxImages, images)
+	}
+
+	return pointCloud, nil
+}
+
+// checkUploadSize is checkUploadFiles without the minimum photo count, which
+// an upload that is still growing file by file may not have reached yet. It
+// also returns the number of photos.
+func (limits uploadLimits) checkUploadSize(files []db.UploadFiles) (string, int, error) {
+	var total int64
+	images := 0
+	pointCloud := ""
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if !uploadFileName.MatchString(file.Name) {
+			return "", 0, fmt.Errorf("invalid file name %q", file.Name)
+		}
+		if seen[file.Name] {
+			return "", 0, fmt.Errorf("file %s is listed twice", file.Name)
+		}
+		seen[file.Name] = true
+
+		if file.Size <= 0 {
+			return "", 0, fmt.Errorf("file %s is empty", file.Name)
+		}
+		total += file.Size
+
+		ext := strings.ToLower(path.Ext(file.Name))
+		switch {
+		case len(uploadImageTypes[ext]) > 0:
+			images++
+		case ext == pointCloudExtension && len(pointCloud) == 0:
+			pointCloud = file.Name
+		case ext == pointCloudExtension:
+			return "", 0, fmt.Errorf("only one point cloud may be uploaded")
+		default:
+			return "", 0, fmt.Errorf("%w: %s, expected jpg, png or a single ply point cloud", errUploadType, file.Name)
+		}
+	}
+
+	if images > limits.MaxImages {
+		return "", 0, fmt.Errorf("%w: an upload may have at most %d photos, not %d", errUploadTooLarge, limits.MaxImages, images)
+	}
+	if total > limits.MaxBytes {
+		return "", 0, fmt.Errorf("%w: %d bytes exceed the limit of %d bytes", errUploadTooLarge, total, limits.MaxBytes)
+	}
+
+	return pointCloud, images, nil
+}
+
+// checkUploadType makes sure the start of the file matches its extension.
+func checkUploadType(name string, head []byte) error {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == pointCloudExtension {
+		if !isPointCloud(head) {
+			return fmt.Errorf("%w: %s is not a ply point cloud", errUploadType, name)
+		}
+		return nil
+	}
+
+	want := uploadImageTypes[ext]
+	if got := http.DetectContentType(head); got != want {
+		return fmt.Errorf("%w: %s contains %s, not %s", errUploadType, name, got, want)
+	}
+
+	return nil
+}
+
+// isPointCloud checks that the file starts with the magic line and format line
+// of a ply header.
+func isPointCloud(head []byte) bool {
+	lines := bytes.SplitN(head, []byte("\n"), 3)
+	if len(lines) < 2 || string(bytes.TrimSpace(lines[0])) != "ply" {
+		return false
+	}
+
+	format := strings.Fields(string(lines[1]))
+	return len(format) >= 2 && format[0] == "format" && plyFormats[format[1]]
+}
+
+// uploadPath is where a file of the session is stored.
+func uploadPath(session db.UploadSessions, name string) string {
+	return session.Dir + "/" + name
+}
+
+// writeUpload streams data into the file of the session starting at offset,
+// which has to be where the previous chunk ended, and records how much of the
+// file was received.
+func (server *Server) writeUpload(ctx context.Context, session db.UploadSessions, file db.UploadFiles, offset int64, data io.Reader) (db.UploadFiles, error) {
+	if offset != file.Received {
+		return file, fmt.Errorf("%w: %s has %d bytes, not %d", errUploadOffset, file.Name, file.Received, offset)
+	}
+
+	reader := bufio.NewReaderSize(data, sniffLen)
+	if offset == 0 {
+		head, err := reader.Peek(sniffLen)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return file, err
+		}
+		if err := checkUploadType(file.Name, head); err != nil {
+			return file, err
+		}
+	}
+
+	n, writeErr := server.storage.Write(ctx, uploadPath(session, file.Name), offset, io.LimitReader(reader, file.Size-offset))
+	if writeErr != nil && n == 0 {
+		return file, writeErr
+	}
+
+	// anything left over does not fit the declared size
+	if writeErr == nil {
+		if _, err := reader.ReadByte(); err == nil {
+			return file, fmt.Errorf("%w: %s was declared with %d bytes", errUploadTooLarge, file.Name, file.Size)
+		}
+	}
+
+	// a connection that dropped mid chunk still keeps what was stored, so
+	// the client resumes from there
+	advanced, err := server.store.AdvanceUploadFile(ctx, db.AdvanceUploadFileParams{
+		UploadSessionsId: session.ID,
+		Name:             file.Name,
+		Received:         offset + n,
+		Received_2:       offset,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return file, fmt.Errorf("%w: another chunk of %s was written meanwhile", errUploadOffset, file.Name)
+		}
+		return file, err
+	}
+
+	return advanced, writeErr
+}
+
+// uploadCheckStatus is the status for a set of files the limits reject.
+func uploadCheckStatus(err error) int {
+	switch {
+	case errors.Is(err, errUploadTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errUploadType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func uploadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errUploadOffset):
+		return http.StatusConflict
+	case errors.Is(err, errUploadTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errUploadType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, storage.ErrUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+type UploadFileResponse struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Received int64  `json:"received"`
+	Complete bool   `json:"complete"`
+}
+
+func ReturnUploadFileResponse(file *db.UploadFiles) UploadFileResponse {
+	return UploadFileResponse{
+		Name:     file.Name,
+		Size:     file.Size,
+		Received: file.Received,
+		Complete: file.Received == file.Size,
+	}
+}
+
+type UploadSessionResponse struct {
+	ID        string               `json:"id"`
+	Title     string               `json:"title"`
+	Type      string               `json:"type"`
+	IsPrivate bool                 `json:"isPrivate"`
+	Tags      []string             `json:"tags"`
+	Status    string               `json:"status"`
+	AssetID   string               `json:"assetId"`
+	Files     []UploadFileResponse `json:"files"`
+	CreatedAt time.Time            `json:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+}
+
+func ReturnUploadSessionResponse(session *db.UploadSessions, files []db.UploadFiles) UploadSessionResponse {
+	res := UploadSessionResponse{
+		ID:        session.ID.String(),
+		Title:     session.Title,
+		Type:      session.Type,
+		IsPrivate: session.IsPrivate,
+		Tags:      session.Tags,
+		Status:    session.Status,
+		Files:     []UploadFileResponse{},
+		CreatedAt: session.CreatedAt,
+		UpdatedAt: session.UpdatedAt,
+	}
+	if session.AssetsId.Valid {
+		res.AssetID = session.AssetsId.UUID.String()
+	}
+	for i := range files {
+		res.Files = append(res.Files, ReturnUploadFileResponse(&files[i]))
+	}
+
+	return res
+}
+
+type UploadFileRequest struct {
+	Name string `json:"name" binding:"required"`
+	Size int64  `json:"size" binding:"required,min=1"`
+}
+
+type CreateUploadSessionRequest struct {
+	Title     string              `json:"title" binding:"required"`
+	IsPrivate *bool               `json:"isPrivate" binding:"required"`
+	Type      string              `json:"type" binding:"required"`
+	Tags      []string            `json:"tags"`
+	Files     []UploadFileRequest `json:"files" binding:"required,min=1,dive"`
+}
+
+type uploadSessionResponse struct {
+	Message string                `json:"message"`
+	Session UploadSessionResponse `json:"session"`
+}
+
+// CreateUploadSession opens an upload of a photo set
+// @Summary Create upload session
+// @Description Creates a draft asset and starts a direct upload of its photos. The files are declared up front with their sizes and checked against the photo count, file type and total size limits. Photos may be jpg or png; a single ply file becomes the point cloud of the asset. Upload the files with PUT /uploads/{id}/files/{name}, POST /uploads/{id}/files or tus, then finalize the session to start processing the asset. Draft assets are only listed to their owner; removing one abandons its upload.
+// @Tags uploads
+// @Accept json
+// @Produce json
+// @Param   request  body   CreateUploadSessionRequest     true  "Create Upload Session Request"
+// @Success 201 {object} uploadSessionResponse "Upload session created"
+// @Failure 400 {object} ErrorResponse "Error: Invalid files or asset type"
+// @Failure 413 {object} ErrorResponse "Error: Too many photos or bytes"
+// @Failure 415 {object} ErrorResponse "Error: Unsupported file type"
+// @Failure 501 {object} ErrorResponse "Error: Storage server does not accept uploads"
+// @Security BearerAuth
+// @Router /uploads [post]
+func (server *Server) createUploadSession(ctx *gin.Context) {
+	payload, err := getUserPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.storage.Supports(storage.CapabilityWrite) {
+		ctx.JSON(http.StatusNotImplemented, errorResponse(fmt.Errorf("%w: uploads need a storage server that accepts writes", storage.ErrUnsupported)))
+		return
+	}
+
+	var req CreateUploadSessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	files := make([]db.UploadFiles, len(req.Files))
+	for i, file := range req.Files {
+		files[i] = db.UploadFiles{Name: file.Name, Size: file.Size}
+	}
+
+	pointCloud, err := server.uploadLimits().checkUploadFiles(files)
+	if err != nil {
+		ctx.JSON(uploadCheckStatus(err), errorResponse(err))
+		return
+	}
+
+	err = server.checkAssetType(req.Type, len(pointCloud) > 0)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	tags := req.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	slug, err := server.assetSlug(ctx, req.Title)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	id := uuid.New()
+	dir := fmt.Sprintf("/files/%s/%s", payload.Uid, id)
+	arg := db.CreateUploadSessionTxParams{
+		CreateUploadSessionParams: db.CreateUploadSessionParams{
+			ID:        id,
+			Uid:       payload.Uid,
+			Title:     req.Title,
+			Type:      req.Type,
+			IsPrivate: *req.IsPrivate,
+			Tags:      tags,
+			Dir:       dir,
+		},
+		// the thumbnail and point cloud are known once the photos are in
+		Asset: db.CreateAssetParams{
+			Uid:         payload.Uid,
+			Title:       req.Title,
+			Slug:        slug,
+			Status:      string(pipeline.StateDraft),
+			PhotoDirUrl: dir,
+			Type:        req.Type,
+			IsPrivate:   *req.IsPrivate,
+		},
+	}
+	for _, file := range req.Files {
+		arg.Files = append(arg.Files, db.CreateUploadFileParams{Name: file.Name, Size: file.Size})
+	}
+
+	result, err := server.store.CreateUploadSessionTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, uploadSessionResponse{
+		Message: "upload session created",
+		Session: ReturnUploadSessionResponse(&result.Session, result.Files),
+	})
+}
+
+// GetUploadSession shows how far an upload got
+// @Summary Get upload session
+// @Description Shows the files of an upload session and how many bytes of each were received, so an interrupted upload can resume at the right offset.
+// @Tags uploads
+// @Produce json
+// @Param   id   path   string  true  "Upload Session ID"
+// @Success 200 {object} uploadSessionResponse "Upload session retrieved"
+// @Failure 404 {object} ErrorResponse "Error: Upload session not found"
+// @Security BearerAuth
+// @Router /uploads/{id} [get]
+func (server *Server) getUploadSession(ctx *gin.Context) {
+	session, ok := server.bindUploadSession(ctx, false)
+	if !ok {
+		return
+	}
+
+	files, err := server.store.ListUploadFiles(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, uploadSessionResponse{
+		Message: "upload session retrieved",
+		Session: ReturnUploadSessionResponse(&session, files),
+	})
+}
+
+type uploadChunkParam struct {
+	Name string `uri:"name" binding:"required"`
+}
+
+type uploadChunkQuery struct {
+	Offset int64 `form:"offset" binding:"min=0"`
+}
+
+type uploadFileResponse struct {
+	Message string             `json:"message"`
+	File    UploadFileResponse `json:"file"`
+}
+
+// UploadChunk stores a chunk of a file
+// @Summary Upload file chunk
+// @Description Streams the request body into a declared file of the upload session, starting at the given offset. The offset must equal the bytes received so far; the first chunk must start with the content the file extension promises.
+// @Tags uploads
+// @Accept application/octet-stream
+// @Produce json
+// @Param   id      path   string  true   "Upload Session ID"
+// @Param   name    path   string  true   "File name"
+// @Param   offset  query  int     false  "Offset of the chunk in the file"
+// @Success 200 {object} uploadFileResponse "Chunk stored"
+// @Failure 404 {object} ErrorResponse "Error: Upload session or file not found"
+// @Failure 409 {object} ErrorResponse "Error: Offset does not match or the session is finalized"
+// @Failure 413 {object} ErrorResponse "Error: File is larger than declared"
+// @Failure 415 {object} ErrorResponse "Error: Unsupported file type"
+// @Security BearerAuth
+// @Router /uploads/{id}/files/{name} [put]
+func (server *Server) uploadChunk(ctx *gin.Context) {
+	session, ok := server.bindUploadSession(ctx, true)
+	if !ok {
+		return
+	}
+
+	var param uploadChunkParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var query uploadChunkQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	file, err := server.store.GetUploadFile(ctx, db.GetUploadFileParams{UploadSessionsId: session.ID, Name: param.Name})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("file %s is not part of the upload", param.Name)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	file, err = server.writeUpload(ctx, session, file, query.Offset, ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(uploadErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, uploadFileResponse{Message: "chunk stored", File: ReturnUploadFileResponse(&file)})
+}
+
+type uploadFilesResponse struct {
+	Message string               `json:"message"`
+	Files   []UploadFileResponse `json:"files"`
+}
+
+// UploadFiles stores whole files sent as a multipart form
+// @Summary Upload files
+// @Description Streams every file part of a multipart/form-data body into the declared file of the same name. Each file is written from the start, so this suits sets small enough to send in one request.
+// @Tags uploads
+// @Accept multipart/form-data
+// @Produce json
+// @Param   id     path      string  true  "Upload Session ID"
+// @Param   files  formData  file    true  "Files of the upload"
+// @Success 200 {object} uploadFilesResponse "Files stored"
+// @Failure 404 {object} ErrorResponse "Error: Upload session or file not found"
+// @Failure 409 {object} ErrorResponse "Error: File was already uploaded or the session is finalized"
+// @Failure 413 {object} ErrorResponse "Error: File is larger than declared"
+// @Failure 415 {object} ErrorResponse "Error: Unsupported file type"
+// @Security BearerAuth
+// @Router /uploads/{id}/files [post]
+func (server *Server) uploadFiles(ctx *gin.Context) {
+	session, ok := server.bindUploadSession(ctx, true)
+	if !ok {
+		return
+	}
+
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	res := uploadFilesResponse{Message: "files stored", Files: []UploadFileResponse{}}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if len(part.FileName()) == 0 {
+			continue
+		}
+
+		file, err := server.store.GetUploadFile(ctx, db.GetUploadFileParams{UploadSessionsId: session.ID, Name: part.FileName()})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("file %s is not part of the upload", part.FileName())))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		file, err = server.writeUpload(ctx, session, file, 0, part)
+		if err != nil {
+			ctx.JSON(uploadErrorStatus(err), errorResponse(err))
+			return
+		}
+		if file.Received != file.Size {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("file %s has %d of %d bytes, continue it with PUT", file.Name, file.Received, file.Size)))
+			return
+		}
+
+		res.Files = append(res.Files, ReturnUploadFileResponse(&file))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// FinalizeUploadSession starts processing the asset of a finished upload
+// @Summary Finalize upload session
+// @Description Checks that every declared file was received in full and moves the draft asset of the session into the first stage of its pipeline, like POST /assets does for a new asset.
+// @Tags uploads
+// @Produce json
+// @Param   id   path   string  true  "Upload Session ID"
+// @Success 202 {object} CreateAssetsResponse "Processing of the uploaded asset started"
+// @Failure 404 {object} ErrorResponse "Error: Upload session not found"
+// @Failure 409 {object} ErrorResponse "Error: Files are incomplete, the session is finalized or its draft asset was removed"
+// @Security BearerAuth
+// @Router /uploads/{id}/finalize [post]
+func (server *Server) finalizeUploadSession(ctx *gin.Context) {
+	session, ok := server.bindUploadSession(ctx, true)
+	if !ok {
+		return
+	}
+
+	files, err := server.store.ListUploadFiles(ctx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	for _, file := range files {
+		if file.Received != file.Size {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("file %s is incomplete, %d of %d bytes were received", file.Name, file.Received, file.Size)))
+			return
+		}
+	}
+
+	pointCloud, err := server.uploadLimits().checkUploadFiles(files)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// files added or removed over tus may have changed the point cloud
+	err = server.checkAssetType(session.Type, len(pointCloud) > 0)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserById(ctx, session.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	thumbnailUrl, err := server.storage.Thumbnail(ctx, session.Dir)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	first := server.pipelines.For(session.Type).First()
+	jobID := uuid.New()
+	arg := db.StartDraftAssetTxParams{
+		UploadSessionID: session.ID,
+		ThumbnailUrl:    thumbnailUrl,
+		Event: func(asset db.Assets) (db.CreateOutboxEventParams, error) {
+			return stageEvent(&asset, first, jobID)
+		},
+		Status: string(first.Stage.State()),
+		Stage:  string(first.Stage),
+		JobID:  jobID,
+		Notify: transitionWebhooks(pipeline.StateDraft, first.Stage.State()),
+	}
+	if len(pointCloud) > 0 {
+		arg.PclUrl = sql.NullString{String: uploadPath(session, pointCloud), Valid: true}
+	}
+
+	asset, err := server.store.StartDraftAssetTx(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("upload session is already finalized")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, CreateAssetsResponse{
+		Message: "generate splat from upload",
+		Asset:   ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user}),
+	})
+}
+
+type uploadSessionParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// bindUploadSession loads the upload session named in the uri, making sure it
+// belongs to the caller and, if open is set, that it still takes files. It
+// writes the error response when that fails.
+func (server *Server) bindUploadSession(ctx *gin.Context, open bool) (db.UploadSessions, bool) {
+	payload, err := getUserPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.UploadSessions{}, false
+	}
+
+	var param uploadSessionParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.UploadSessions{}, false
+	}
+
+	session, err := server.store.GetUploadSessionById(ctx, uuid.MustParse(param.ID))
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return session, false
+	}
+	if err == sql.ErrNoRows || session.Uid != payload.Uid {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("upload session is not found")))
+		return session, false
+	}
+
+	if open && session.Status != uploadStatusOpen {
+		ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("upload session is %s", session.Status)))
+		return session, false
+	}
+
+	// removing the draft asset abandons the upload
+	if open && !session.AssetsId.Valid {
+		ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("the draft asset of the upload session was removed")))
+		return session, false
+	}
+
+	return session, true
+}
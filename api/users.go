@@ -20,6 +20,7 @@ type UserResponse struct {
 	CreatedAt         time.Time `json:"createdAt"`
 	UpdatedAt         time.Time `json:"updatedAt"`
 	PasswordChangedAt time.Time `json:"passwordChangedAt"`
+	Role              string    `json:"role"`
 }
 
 func ReturnUserResponse(user *db.Users) *UserResponse {
@@ -32,6 +33,7 @@ func ReturnUserResponse(user *db.Users) *UserResponse {
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 		UpdatedAt:         user.UpdatedAt,
+		Role:              user.Role,
 	}
 }
 
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+	"github.com/segment3d-app/segment3d-be/util"
+)
+
+const (
+	// watchdogLockKey is the advisory lock that keeps replicas from sweeping
+	// at the same time.
+	watchdogLockKey int64 = 0x736733647764
+
+	defaultWatchdogInterval = time.Minute
+	defaultStageTimeout     = 6 * time.Hour
+	defaultStageMaxAttempts = 3
+)
+
+type watchdogConfig struct {
+	interval    time.Duration
+	timeouts    map[pipeline.Stage]time.Duration
+	maxAttempts int
+}
+
+func newWatchdogConfig(config *util.Config) (watchdogConfig, error) {
+	watchdog := watchdogConfig{
+		interval:    config.WatchdogInterval,
+		timeouts:    make(map[pipeline.Stage]time.Duration),
+		maxAttempts: config.StageMaxAttempts,
+	}
+	if watchdog.interval <= 0 {
+		watchdog.interval = defaultWatchdogInterval
+	}
+	if watchdog.maxAttempts <= 0 {
+		watchdog.maxAttempts = defaultStageMaxAttempts
+	}
+
+	timeouts, err := util.ParseStageTimeouts(config.StageTimeouts)
+	if err != nil {
+		return watchdog, err
+	}
+
+	for _, stage := range pipeline.Stages {
+		watchdog.timeouts[stage] = defaultStageTimeout
+	}
+	for name, timeout := range timeouts {
+		stage, err := pipeline.ParseStage(name)
+		if err != nil {
+			return watchdog, err
+		}
+		watchdog.timeouts[stage] = timeout
+	}
+
+	return watchdog, nil
+}
+
+// RunWatchdog periodically looks for assets stuck in a stage until the
+// context is cancelled.
+func (server *Server) RunWatchdog(ctx context.Context) {
+	ticker := time.NewTicker(server.watchdog.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, err := server.store.WithAdvisoryLock(ctx, watchdogLockKey, func() error {
+			return server.sweepStuckAssets(ctx)
+		})
+		if err != nil {
+			log.Printf("can't sweep stuck assets: %v", err)
+		}
+	}
+}
+
+// sweepStuckAssets re-enqueues every asset that exceeded the timeout of its
+// current stage, or fails it once the stage ran out of attempts.
+func (server *Server) sweepStuckAssets(ctx context.Context) error {
+	for _, stage := range pipeline.Stages {
+		timeout := server.watchdog.timeouts[stage]
+
+		assets, err := server.store.ListStaleAssetsByStatus(ctx, db.ListStaleAssetsByStatusParams{
+			Status:    string(stage.State()),
+			UpdatedAt: time.Now().Add(-timeout),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, asset := range assets {
+			// only the attempts since the asset last entered the stage count,
+			// so a reprocessed asset gets a fresh set of retries
+			attempts, err := server.store.CountStageRunJobs(ctx, db.CountStageRunJobsParams{
+				AssetsId: asset.ID,
+				Stage:    string(stage),
+				ToStatus: asset.Status,
+			})
+			if err != nil {
+				return err
+			}
+
+			if int(attempts) < server.watchdog.maxAttempts {
+				err = server.requeueStage(ctx, asset, stage)
+			} else {
+				reason := fmt.Sprintf("%s timed out after %s, giving up after %d attempts", stage, timeout, attempts)
+				_, err = server.markAssetFailed(ctx, asset, stage, reason, "", db.TransitionAssetTxParams{})
+			}
+			if err != nil {
+				log.Printf("can't recover asset %s stuck in %s: %v", asset.ID, stage, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// requeueStage publishes the stage of the asset again. The asset stays in the
+// same state, so the pipeline transition table is not consulted, but the
+// status update still only applies if nothing moved the asset meanwhile.
+func (server *Server) requeueStage(ctx context.Context, asset db.Assets, stage pipeline.Stage) error {
+	step, ok := server.pipelines.For(asset.Type).Step(stage)
+	if !ok {
+		return fmt.Errorf("assets of type %s do not run %s", asset.Type, stage)
+	}
+
+	jobID := uuid.New()
+	event, err := stageEvent(&asset, step, jobID)
+	if err != nil {
+		return err
+	}
+
+	_, err = server.store.TransitionAssetTx(ctx, db.TransitionAssetTxParams{
+		ID:         asset.ID,
+		FromStatus: asset.Status,
+		ToStatus:   asset.Status,
+		Event:      &event,
+		CloseJobs: &db.CloseOpenJobsParams{
+			AssetsId: asset.ID,
+			Status:   jobStatusFailed,
+			Error:    sql.NullString{String: fmt.Sprintf("timed out after %s", server.watchdog.timeouts[stage]), Valid: true},
+		},
+		EnqueueStage: string(stage),
+		JobID:        jobID,
+	})
+	if err == sql.ErrNoRows {
+		// a result arrived while sweeping
+		return nil
+	}
+
+	return err
+}
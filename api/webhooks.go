@@ -0,0 +1,437 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+	"github.com/segment3d-app/segment3d-be/webhook"
+)
+
+const (
+	webhookEventStatusChanged = "asset.status_changed"
+	webhookEventCompleted     = "asset.completed"
+	webhookEventFailed        = "asset.failed"
+	webhookEventDeleted       = "asset.deleted"
+
+	// defaultWebhookDeliveryLimit is the number of deliveries listed per
+	// webhook.
+	defaultWebhookDeliveryLimit = 50
+)
+
+type WebhookResponse struct {
+	ID        string    `json:"id"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	IsActive  bool      `json:"isActive"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func ReturnWebhookResponse(hook *db.Webhooks) WebhookResponse {
+	return WebhookResponse{
+		ID:        hook.ID.String(),
+		Url:       hook.Url,
+		Events:    hook.Events,
+		IsActive:  hook.IsActive,
+		CreatedAt: hook.CreatedAt,
+		UpdatedAt: hook.UpdatedAt,
+	}
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus int32           `json:"responseStatus"`
+	LastError      string          `json:"lastError"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+func ReturnWebhookDeliveryResponse(delivery *db.WebhookDeliveries) WebhookDeliveryResponse {
+	res := WebhookDeliveryResponse{
+		ID:             delivery.ID.String(),
+		Event:          delivery.Event,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus.Int32,
+		LastError:      delivery.LastError.String,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	if delivery.DeliveredAt.Valid {
+		res.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+
+	return res
+}
+
+// WebhookPayload is the body posted to a webhook endpoint.
+type WebhookPayload struct {
+	Event      string        `json:"event"`
+	OccurredAt time.Time     `json:"occurredAt"`
+	FromStatus string        `json:"fromStatus,omitempty"`
+	ToStatus   string        `json:"toStatus,omitempty"`
+	Asset      AssetResponse `json:"asset"`
+}
+
+// transitionWebhooks returns the Notify hook of a transaction that moves an
+// asset from one state to another. It queues the webhooks interested in the
+// status change within the transaction, so the deliveries are recorded
+// exactly when the change is committed.
+func transitionWebhooks(from pipeline.State, to pipeline.State) func(ctx context.Context, q db.Querier, asset db.Assets) error {
+	return func(ctx context.Context, q db.Querier, asset db.Assets) error {
+		events := []string{webhookEventStatusChanged}
+		switch to {
+		case pipeline.StateCompleted:
+			events = append(events, webhookEventCompleted)
+		case pipeline.StateFailed:
+			events = append(events, webhookEventFailed)
+		}
+
+		for _, event := range events {
+			err := enqueueWebhooks(ctx, q, asset, WebhookPayload{
+				Event:      event,
+				FromStatus: string(from),
+				ToStatus:   string(to),
+			})
+			if err != nil {
+				return fmt.Errorf("can't queue %s webhooks: %w", event, err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// deletedWebhooks is the Notify hook of the transaction removing an asset. It
+// queues the webhooks interested in the removal.
+func deletedWebhooks(ctx context.Context, q db.Querier, asset db.Assets) error {
+	err := enqueueWebhooks(ctx, q, asset, WebhookPayload{Event: webhookEventDeleted})
+	if err != nil {
+		return fmt.Errorf("can't queue %s webhooks: %w", webhookEventDeleted, err)
+	}
+
+	return nil
+}
+
+// enqueueWebhooks records a delivery of the payload for every active webhook
+// of the asset owner subscribed to its event.
+func enqueueWebhooks(ctx context.Context, q db.Querier, asset db.Assets, payload WebhookPayload) error {
+	hooks, err := q.ListWebhooksForEvent(ctx, db.ListWebhooksForEventParams{
+		Uid:     asset.Uid,
+		Column2: payload.Event,
+	})
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+
+	user, err := q.GetUserById(ctx, asset.Uid)
+	if err != nil {
+		return err
+	}
+
+	payload.OccurredAt = time.Now()
+	payload.Asset = ReturnAssetResponse(ReturnAssetResponseArg{Asset: &asset, User: &user})
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		_, err := q.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			WebhooksId: hook.ID,
+			Event:      payload.Event,
+			Payload:    body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+type CreateWebhookRequest struct {
+	Url    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=asset.status_changed asset.completed asset.failed asset.deleted"`
+}
+
+type CreateWebhookResponse struct {
+	Message string          `json:"message"`
+	Webhook WebhookResponse `json:"webhook"`
+	// Secret is only returned once, when the webhook is created.
+	Secret string `json:"secret"`
+}
+
+// CreateWebhook registers a webhook
+// @Summary Create webhook
+// @Description Registers an endpoint that receives the selected events of my assets. Every request is signed with the returned secret: the X-Segment3d-Timestamp header holds the unix time it was sent at and the X-Segment3d-Signature header holds sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">. Reject requests with an old timestamp to guard against replays. The url has to resolve to a public address.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param   request  body   CreateWebhookRequest     true  "Create Webhook Request"
+// @Success 201 {object} CreateWebhookResponse "Webhook created"
+// @Failure 422 {object} ErrorResponse "Error: Url does not point at a public address"
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (server *Server) createWebhook(ctx *gin.Context) {
+	payload, err := getUserPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	err = webhook.CheckURL(ctx, req.Url)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hook, err := server.store.CreateWebhook(ctx, db.CreateWebhookParams{
+		Uid:    payload.Uid,
+		Url:    req.Url,
+		Secret: secret,
+		Events: req.Events,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, CreateWebhookResponse{
+		Message: "webhook created",
+		Webhook: ReturnWebhookResponse(&hook),
+		Secret:  secret,
+	})
+}
+
+type getWebhooksResponse struct {
+	Message  string            `json:"message"`
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// GetWebhooks lists my webhooks
+// @Summary Get my webhooks
+// @Description Lists the webhooks I registered
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} getWebhooksResponse "Webhooks retrieved"
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (server *Server) getWebhooks(ctx *gin.Context) {
+	payload, err := getUserPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hooks, err := server.store.ListWebhooksByUser(ctx, payload.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := getWebhooksResponse{Message: "webhooks retrieved", Webhooks: []WebhookResponse{}}
+	for i := range hooks {
+		res.Webhooks = append(res.Webhooks, ReturnWebhookResponse(&hooks[i]))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type webhookParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type UpdateWebhookRequest struct {
+	Url      string   `json:"url" binding:"omitempty,url"`
+	Events   []string `json:"events" binding:"omitempty,min=1,dive,oneof=asset.status_changed asset.completed asset.failed asset.deleted"`
+	IsActive *bool    `json:"isActive"`
+}
+
+type webhookResponse struct {
+	Message string          `json:"message"`
+	Webhook WebhookResponse `json:"webhook"`
+}
+
+// UpdateWebhook updates my webhook
+// @Summary Update webhook
+// @Description Changes the url, the events or pauses a webhook. Omitted fields are left unchanged.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param   id   path   string     true  "Webhook ID"
+// @Param   request  body   UpdateWebhookRequest     true  "Update Webhook Request"
+// @Success 200 {object} webhookResponse "Webhook updated"
+// @Failure 404 {object} ErrorResponse "Error: Webhook not found"
+// @Failure 422 {object} ErrorResponse "Error: Url does not point at a public address"
+// @Security BearerAuth
+// @Router /webhooks/{id} [patch]
+func (server *Server) updateWebhook(ctx *gin.Context) {
+	hook, ok := server.bindWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	var req UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.UpdateWebhookParams{
+		ID:       hook.ID,
+		Url:      hook.Url,
+		Events:   hook.Events,
+		IsActive: hook.IsActive,
+	}
+	if len(req.Url) > 0 {
+		err := webhook.CheckURL(ctx, req.Url)
+		if err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		arg.Url = req.Url
+	}
+	if len(req.Events) > 0 {
+		arg.Events = req.Events
+	}
+	if req.IsActive != nil {
+		arg.IsActive = *req.IsActive
+	}
+
+	hook, err := server.store.UpdateWebhook(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhookResponse{Message: "webhook updated", Webhook: ReturnWebhookResponse(&hook)})
+}
+
+// DeleteWebhook removes my webhook
+// @Summary Delete webhook
+// @Description Removes a webhook together with its delivery log
+// @Tags webhooks
+// @Produce json
+// @Param   id   path   string     true  "Webhook ID"
+// @Success 200 {object} webhookResponse "Webhook deleted"
+// @Failure 404 {object} ErrorResponse "Error: Webhook not found"
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func (server *Server) deleteWebhook(ctx *gin.Context) {
+	hook, ok := server.bindWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	err := server.store.DeleteWebhook(ctx, hook.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhookResponse{Message: "webhook deleted", Webhook: ReturnWebhookResponse(&hook)})
+}
+
+type getWebhookDeliveriesResponse struct {
+	Message    string                    `json:"message"`
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+// GetWebhookDeliveries lists the deliveries of my webhook
+// @Summary Get webhook deliveries
+// @Description Lists the latest 50 deliveries of a webhook with their status, attempts and last error
+// @Tags webhooks
+// @Produce json
+// @Param   id   path   string     true  "Webhook ID"
+// @Success 200 {object} getWebhookDeliveriesResponse "Deliveries retrieved"
+// @Failure 404 {object} ErrorResponse "Error: Webhook not found"
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (server *Server) getWebhookDeliveries(ctx *gin.Context) {
+	hook, ok := server.bindWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		WebhooksId: hook.ID,
+		Limit:      defaultWebhookDeliveryLimit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := getWebhookDeliveriesResponse{Message: "deliveries retrieved", Deliveries: []WebhookDeliveryResponse{}}
+	for i := range deliveries {
+		res.Deliveries = append(res.Deliveries, ReturnWebhookDeliveryResponse(&deliveries[i]))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// bindWebhook loads the webhook named in the uri, making sure it belongs to
+// the caller, and writes the error response when that fails.
+func (server *Server) bindWebhook(ctx *gin.Context) (db.Webhooks, bool) {
+	payload, err := getUserPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Webhooks{}, false
+	}
+
+	var param webhookParam
+	if err := ctx.ShouldBindUri(&param); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.Webhooks{}, false
+	}
+
+	hook, err := server.store.GetWebhookById(ctx, uuid.MustParse(param.ID))
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hook, false
+	}
+	if err == sql.ErrNoRows || hook.Uid != payload.Uid {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("webhook is not found")))
+		return hook, false
+	}
+
+	return hook, true
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/pipeline"
+)
+
+const (
+	// defaultWorkerStaleAfter is how long a worker may go without a heartbeat
+	// before it is reported as stale.
+	defaultWorkerStaleAfter = 2 * time.Minute
+	// throughputWindow is the period finished jobs are counted over to
+	// estimate how fast each stage drains its queue.
+	throughputWindow = time.Hour
+)
+
+// workerCallbackParams records which worker delivered a pipeline result for
+// the asset.
+func workerCallbackParams(assetID uuid.UUID, workerID string, endpoint string, url string) *db.CreateWorkerCallbackParams {
+	return &db.CreateWorkerCallbackParams{
+		AssetsId: assetID,
+		WorkerId: workerID,
+		Endpoint: endpoint,
+		Url:      url,
+	}
+}
+
+type WorkerResponse struct {
+	ID             string    `json:"id"`
+	Stages         []string  `json:"stages"`
+	GpuModel       string    `json:"gpuModel"`
+	CurrentAssetID string    `json:"currentAssetId"`
+	CurrentStage   string    `json:"currentStage"`
+	RegisteredAt   time.Time `json:"registeredAt"`
+	LastSeenAt     time.Time `json:"lastSeenAt"`
+	Healthy        bool      `json:"healthy"`
+}
+
+func ReturnWorkerResponse(worker *db.Workers, staleAfter time.Duration) WorkerResponse {
+	res := WorkerResponse{
+		ID:           worker.ID,
+		Stages:       worker.Stages,
+		GpuModel:     worker.GpuModel.String,
+		CurrentStage: worker.CurrentStage.String,
+		RegisteredAt: worker.RegisteredAt,
+		LastSeenAt:   worker.LastSeenAt,
+		Healthy:      time.Since(worker.LastSeenAt) < staleAfter,
+	}
+	if worker.CurrentAssetsId.Valid {
+		res.CurrentAssetID = worker.CurrentAssetsId.UUID.String()
+	}
+
+	return res
+}
+
+// workerStaleAfter returns how long a worker may go without a heartbeat.
+func (server *Server) workerStaleAfter() time.Duration {
+	if server.config.WorkerStaleAfter <= 0 {
+		return defaultWorkerStaleAfter
+	}
+
+	return server.config.WorkerStaleAfter
+}
+
+type RegisterWorkerRequest struct {
+	Stages   []string `json:"stages" binding:"required,min=1,dive,oneof=colmap splat ptv3 saga"`
+	GpuModel string   `json:"gpuModel"`
+}
+
+type workerResponse struct {
+	Message string         `json:"message"`
+	Worker  WorkerResponse `json:"worker"`
+}
+
+// RegisterWorker records a worker and the stages it runs
+// @Summary Register worker
+// @Description Called by a GPU worker on startup. Records the stages it runs and its GPU model under the worker id of its token and clears its current job.
+// @Tags workers
+// @Accept json
+// @Produce json
+// @Param   request  body   RegisterWorkerRequest     true  "Register Worker Request"
+// @Success 200 {object} workerResponse "Worker registered"
+// @Security WorkerAuth
+// @Router /workers/register [post]
+func (server *Server) registerWorker(ctx *gin.Context) {
+	var req RegisterWorkerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	workerID, err := getWorkerID(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	worker, err := server.store.RegisterWorker(ctx, db.RegisterWorkerParams{
+		ID:       workerID,
+		Stages:   req.Stages,
+		GpuModel: sql.NullString{String: req.GpuModel, Valid: len(req.GpuModel) > 0},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, workerResponse{
+		Message: "worker registered",
+		Worker:  ReturnWorkerResponse(&worker, server.workerStaleAfter()),
+	})
+}
+
+type HeartbeatWorkerRequest struct {
+	AssetID string `json:"assetId" binding:"omitempty,uuid"`
+	Stage   string `json:"stage" binding:"omitempty,oneof=colmap splat ptv3 saga"`
+}
+
+// HeartbeatWorker records that a worker is alive
+// @Summary Worker heartbeat
+// @Description Called by a registered GPU worker periodically. Records the asset and stage it is working on, or that it is idle when both are omitted.
+// @Tags workers
+// @Accept json
+// @Produce json
+// @Param   request  body   HeartbeatWorkerRequest     false  "Heartbeat Worker Request"
+// @Success 200 {object} workerResponse "Heartbeat recorded"
+// @Failure 404 {object} ErrorResponse "Error: Worker is not registered"
+// @Security WorkerAuth
+// @Router /workers/heartbeat [post]
+func (server *Server) heartbeatWorker(ctx *gin.Context) {
+	var req HeartbeatWorkerRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	if len(req.AssetID) > 0 && len(req.Stage) == 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("stage is required with assetId")))
+		return
+	}
+
+	workerID, err := getWorkerID(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.HeartbeatWorkerParams{
+		ID:           workerID,
+		CurrentStage: sql.NullString{String: req.Stage, Valid: len(req.Stage) > 0},
+	}
+	if len(req.AssetID) > 0 {
+		arg.CurrentAssetsId = uuid.NullUUID{UUID: uuid.MustParse(req.AssetID), Valid: true}
+	}
+
+	worker, err := server.store.HeartbeatWorker(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("worker %s is not registered", workerID)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, workerResponse{
+		Message: "heartbeat recorded",
+		Worker:  ReturnWorkerResponse(&worker, server.workerStaleAfter()),
+	})
+}
+
+type StageCapacityResponse struct {
+	Stage          string `json:"stage"`
+	Queued         int64  `json:"queued"`
+	Running        int64  `json:"running"`
+	HealthyWorkers int    `json:"healthyWorkers"`
+	// SucceededLastHour is the number of jobs of the stage that finished
+	// successfully in the last hour.
+	SucceededLastHour int64 `json:"succeededLastHour"`
+	// EstimatedWaitSeconds is how long a job queued now waits at the current
+	// throughput. It is null when nothing finished recently to base it on.
+	EstimatedWaitSeconds *float64 `json:"estimatedWaitSeconds"`
+}
+
+type listWorkersResponse struct {
+	Message string                  `json:"message"`
+	Workers []WorkerResponse        `json:"workers"`
+	Stages  []StageCapacityResponse `json:"stages"`
+}
+
+// ListWorkers lists the registered workers and the load of each stage
+// @Summary List workers
+// @Description Lists registered workers, most recently seen first, marking those without a recent heartbeat as unhealthy. Also reports the queued and running jobs of each stage and estimates the queue wait from the throughput of the last hour.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} listWorkersResponse "Workers retrieved"
+// @Failure 403 {object} ErrorResponse "Error: Admin role is required"
+// @Security BearerAuth
+// @Router /admin/workers [get]
+func (server *Server) listWorkers(ctx *gin.Context) {
+	workers, err := server.store.ListWorkers(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	stats, err := server.store.ListStageJobStats(ctx, sql.NullTime{Time: time.Now().Add(-throughputWindow), Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := listWorkersResponse{
+		Message: "workers retrieved",
+		Workers: []WorkerResponse{},
+		Stages:  []StageCapacityResponse{},
+	}
+
+	healthy := make(map[string]int)
+	for i := range workers {
+		worker := ReturnWorkerResponse(&workers[i], server.workerStaleAfter())
+		if worker.Healthy {
+			for _, stage := range worker.Stages {
+				healthy[stage]++
+			}
+		}
+		res.Workers = append(res.Workers, worker)
+	}
+
+	byStage := make(map[string]db.ListStageJobStatsRow)
+	for _, row := range stats {
+		byStage[row.Stage] = row
+	}
+
+	for _, stage := range pipeline.Stages {
+		row := byStage[string(stage)]
+		capacity := StageCapacityResponse{
+			Stage:             string(stage),
+			Queued:            row.Queued,
+			Running:           row.Running,
+			HealthyWorkers:    healthy[string(stage)],
+			SucceededLastHour: row.Succeeded,
+		}
+		if row.Queued == 0 || row.Succeeded > 0 {
+			wait := float64(row.Queued) * throughputWindow.Seconds() / float64(max(row.Succeeded, 1))
+			capacity.EstimatedWaitSeconds = &wait
+		}
+		res.Stages = append(res.Stages, capacity)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,175 @@
+package cleanup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/storage"
+)
+
+const (
+	StatusPending = "pending"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+
+	pollInterval = 10 * time.Second
+	batchSize    = 20
+	// leaseDuration keeps a claimed job from being run twice at once. It is
+	// generous since a job deletes one path after the other.
+	leaseDuration = 5 * time.Minute
+	// a job is given up after maxAttempts, which with the backoff below spans
+	// roughly a day, and what it could not delete is reported as orphaned
+	maxAttempts = 10
+	minBackoff  = time.Minute
+	maxBackoff  = 6 * time.Hour
+)
+
+// Cleaner deletes the files of removed assets from the storage. Each removed
+// asset queues a cleanup job with its paths; paths that fail are retried with
+// exponential backoff until the job runs out of attempts. Paths the asset
+// pointed at but did not own are never deleted; the job fails once the rest
+// is gone, so they are reported as orphaned.
+type Cleaner struct {
+	store   db.Store
+	storage storage.Storage
+}
+
+func NewCleaner(store db.Store, storage storage.Storage) *Cleaner {
+	return &Cleaner{store: store, storage: storage}
+}
+
+// Run works through pending cleanup jobs until the context is cancelled.
+func (cleaner *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := cleaner.cleanPending(ctx)
+			if err != nil {
+				log.Printf("can't clean up removed assets: %v", err)
+			}
+			if err != nil || n < batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (cleaner *Cleaner) cleanPending(ctx context.Context) (int, error) {
+	jobs, err := cleaner.store.ClaimCleanupJobs(ctx, db.ClaimCleanupJobsParams{
+		Limit:         batchSize,
+		NextAttemptAt: time.Now().Add(leaseDuration),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, job := range jobs {
+		remaining, err := cleaner.clean(ctx, job)
+		if err == nil && len(job.Orphaned) > 0 {
+			err = cleaner.reportOrphaned(ctx, job)
+			if err != nil {
+				return len(jobs), err
+			}
+			continue
+		}
+		if err == nil {
+			err = cleaner.store.MarkCleanupJobDone(ctx, job.ID)
+			if err != nil {
+				return len(jobs), err
+			}
+			continue
+		}
+
+		arg := db.MarkCleanupJobFailedParams{
+			ID:            job.ID,
+			Status:        StatusPending,
+			Remaining:     remaining,
+			LastError:     sql.NullString{String: err.Error(), Valid: true},
+			NextAttemptAt: time.Now().Add(backoff(job.Attempts)),
+		}
+		// a storage that cannot delete will not learn to on a retry
+		if job.Attempts >= maxAttempts || errors.Is(err, storage.ErrUnsupported) {
+			arg.Status = StatusFailed
+			log.Printf("gave up cleaning up asset %s, orphaned files: %s", job.AssetsId, strings.Join(remaining, ", "))
+		}
+
+		err = cleaner.store.MarkCleanupJobFailed(ctx, arg)
+		if err != nil {
+			return len(jobs), err
+		}
+	}
+
+	return len(jobs), nil
+}
+
+// reportOrphaned ends the job as failed once its paths are deleted, so the
+// paths the asset pointed at but did not own are listed for an admin.
+func (cleaner *Cleaner) reportOrphaned(ctx context.Context, job db.CleanupJobs) error {
+	log.Printf("cleaned up asset %s, left orphaned files it does not own: %s", job.AssetsId, strings.Join(job.Orphaned, ", "))
+
+	return cleaner.store.MarkCleanupJobFailed(ctx, db.MarkCleanupJobFailedParams{
+		ID:            job.ID,
+		Status:        StatusFailed,
+		Remaining:     []string{},
+		LastError:     sql.NullString{String: "left files outside of the storage of the asset in place", Valid: true},
+		NextAttemptAt: time.Now(),
+	})
+}
+
+// clean deletes the remaining paths of the job and returns the ones that
+// could not be deleted along with the last error.
+func (cleaner *Cleaner) clean(ctx context.Context, job db.CleanupJobs) ([]string, error) {
+	failed := []string{}
+	var lastErr error
+	for _, path := range job.Remaining {
+		err := cleaner.delete(ctx, path)
+		if err != nil {
+			failed = append(failed, path)
+			lastErr = fmt.Errorf("can't delete %s: %w", path, err)
+		}
+	}
+
+	return failed, lastErr
+}
+
+// delete removes the path unless another asset still uses it, as when two
+// assets were created from the same photos. Assets may store their photos and
+// point cloud as full urls of the storage server, so they are compared by the
+// storage path the urls point at.
+func (cleaner *Cleaner) delete(ctx context.Context, path string) error {
+	users, err := cleaner.store.CountAssetsUsingPath(ctx, path)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return nil
+	}
+
+	return cleaner.storage.Delete(ctx, path)
+}
+
+func backoff(attempts int32) time.Duration {
+	delay := minBackoff
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "workerCallbacks";
\ No newline at end of file
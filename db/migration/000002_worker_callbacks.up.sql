@@ -0,0 +1,10 @@
+CREATE TABLE "workerCallbacks" (
+    "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "assetsId" UUID NOT NULL,
+    "workerId" VARCHAR(255) NOT NULL,
+    "endpoint" VARCHAR(255) NOT NULL,
+    "url" VARCHAR(255) NOT NULL,
+    "createdAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    FOREIGN KEY ("assetsId") REFERENCES "assets"("id") ON DELETE CASCADE
+);
+CREATE INDEX ON "workerCallbacks" ("assetsId");
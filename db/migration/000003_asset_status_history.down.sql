@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "assetStatusHistory";
\ No newline at end of file
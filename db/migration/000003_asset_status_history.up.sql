@@ -0,0 +1,9 @@
+CREATE TABLE "assetStatusHistory" (
+    "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "assetsId" UUID NOT NULL,
+    "fromStatus" VARCHAR(255) NOT NULL,
+    "toStatus" VARCHAR(255) NOT NULL,
+    "createdAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    FOREIGN KEY ("assetsId") REFERENCES "assets"("id") ON DELETE CASCADE
+);
+CREATE INDEX ON "assetStatusHistory" ("assetsId", "createdAt");
@@ -0,0 +1,3 @@
+ALTER TABLE "assets" DROP COLUMN IF EXISTS "failureStage",
+    DROP COLUMN IF EXISTS "failureReason",
+    DROP COLUMN IF EXISTS "failureLogsUrl";
\ No newline at end of file
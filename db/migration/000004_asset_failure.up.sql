@@ -0,0 +1,4 @@
+ALTER TABLE "assets"
+ADD COLUMN "failureStage" VARCHAR(255),
+    ADD COLUMN "failureReason" TEXT,
+    ADD COLUMN "failureLogsUrl" VARCHAR(255);
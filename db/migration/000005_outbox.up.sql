@@ -0,0 +1,12 @@
+CREATE TABLE "outbox" (
+    "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "queue" VARCHAR(255) NOT NULL,
+    "payload" JSONB NOT NULL,
+    "attempts" INT NOT NULL DEFAULT 0,
+    "lastError" TEXT,
+    "nextAttemptAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    "sentAt" TIMESTAMP WITH TIME ZONE,
+    "createdAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX ON "outbox" ("nextAttemptAt")
+WHERE "sentAt" IS NULL;
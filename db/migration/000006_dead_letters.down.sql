@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "deadLetters";
\ No newline at end of file
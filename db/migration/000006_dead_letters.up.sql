@@ -0,0 +1,11 @@
+CREATE TABLE "deadLetters" (
+    "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "queue" VARCHAR(255) NOT NULL,
+    "assetsId" UUID REFERENCES "assets"("id") ON DELETE CASCADE,
+    "reason" VARCHAR(255) NOT NULL,
+    "error" TEXT,
+    "deathCount" INT NOT NULL DEFAULT 1,
+    "payload" TEXT NOT NULL,
+    "createdAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX ON "deadLetters" ("assetsId");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "webhookDeliveries";
+DROP TABLE IF EXISTS "webhooks";
\ No newline at end of file
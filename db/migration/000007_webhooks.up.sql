@@ -0,0 +1,27 @@
+CREATE TABLE "webhooks" (
+    "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "uid" UUID REFERENCES "users"("uid") ON DELETE CASCADE NOT NULL,
+    "url" VARCHAR(255) NOT NULL,
+    "secret" VARCHAR(255) NOT NULL,
+    "events" VARCHAR(255) [] NOT NULL,
+    "isActive" BOOLEAN NOT NULL DEFAULT TRUE,
+    "createdAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    "updatedAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX ON "webhooks" ("uid");
+CREATE TABLE "webhookDeliveries" (
+    "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "webhooksId" UUID REFERENCES "webhooks"("id") ON DELETE CASCADE NOT NULL,
+    "event" VARCHAR(255) NOT NULL,
+    "payload" JSONB NOT NULL,
+    "status" VARCHAR(255) NOT NULL DEFAULT 'pending', -- pending, delivered, failed
+    "attempts" INT NOT NULL DEFAULT 0,
+    "responseStatus" INT,
+    "lastError" TEXT,
+    "nextAttemptAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    "deliveredAt" TIMESTAMP WITH TIME ZONE,
+    "createdAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX ON "webhookDeliveries" ("webhooksId", "createdAt");
+CREATE INDEX ON "webhookDeliveries" ("nextAttemptAt")
+WHERE "status" = 'pending';
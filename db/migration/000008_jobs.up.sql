@@ -0,0 +1,14 @@
+CREATE TABLE "jobs" (
+    "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "assetsId" UUID REFERENCES "assets"("id") ON DELETE CASCADE NOT NULL,
+    "stage" VARCHAR(255) NOT NULL,
+    "attempt" INT NOT NULL,
+    "status" VARCHAR(255) NOT NULL DEFAULT 'queued', -- queued, running, succeeded, failed, cancelled
+    "workerId" VARCHAR(255),
+    "resultUrl" VARCHAR(255),
+    "error" TEXT,
+    "enqueuedAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    "startedAt" TIMESTAMP WITH TIME ZONE,
+    "finishedAt" TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX ON "jobs" ("assetsId", "stage");
@@ -0,0 +1,2 @@
+ALTER TABLE "outbox"
+ADD COLUMN "exchange" VARCHAR(255) NOT NULL DEFAULT '';
\ No newline at end of file
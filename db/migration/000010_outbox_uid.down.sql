@@ -0,0 +1 @@
+ALTER TABLE "outbox" DROP COLUMN IF EXISTS "uid";
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE "outbox"
+ADD COLUMN "uid" UUID;
+CREATE INDEX ON "outbox" ("uid")
+WHERE "sentAt" IS NULL;
\ No newline at end of file
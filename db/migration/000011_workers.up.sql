@@ -0,0 +1,9 @@
+CREATE TABLE "workers" (
+    "id" VARCHAR(255) PRIMARY KEY,
+    "stages" VARCHAR(255) [] NOT NULL DEFAULT '{}',
+    "gpuModel" VARCHAR(255),
+    "currentAssetsId" UUID,
+    "currentStage" VARCHAR(255),
+    "registeredAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    "lastSeenAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
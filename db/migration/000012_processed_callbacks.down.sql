@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "processedCallbacks";
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE "processedCallbacks" (
+    "key" VARCHAR(255) PRIMARY KEY,
+    "assetsId" UUID REFERENCES "assets"("id") ON DELETE CASCADE NOT NULL,
+    "workerId" VARCHAR(255) NOT NULL,
+    "createdAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE "assets" DROP COLUMN IF EXISTS "progressStage",
+    DROP COLUMN IF EXISTS "progressPercent",
+    DROP COLUMN IF EXISTS "progressIteration",
+    DROP COLUMN IF EXISTS "progressEtaSeconds",
+    DROP COLUMN IF EXISTS "progressPreviewUrl",
+    DROP COLUMN IF EXISTS "progressUpdatedAt";
\ No newline at end of file
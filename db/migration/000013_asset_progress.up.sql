@@ -0,0 +1,7 @@
+ALTER TABLE "assets"
+ADD COLUMN "progressStage" VARCHAR(255),
+    ADD COLUMN "progressPercent" DOUBLE PRECISION,
+    ADD COLUMN "progressIteration" INTEGER,
+    ADD COLUMN "progressEtaSeconds" INTEGER,
+    ADD COLUMN "progressPreviewUrl" VARCHAR(255),
+    ADD COLUMN "progressUpdatedAt" TIMESTAMP WITH TIME ZONE;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "uploadFiles";
+DROP TABLE IF EXISTS "uploadSessions";
\ No newline at end of file
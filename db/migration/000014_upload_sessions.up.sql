@@ -0,0 +1,23 @@
+CREATE TABLE "uploadSessions" (
+    "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "uid" UUID REFERENCES "users"("uid") ON DELETE CASCADE NOT NULL,
+    "title" VARCHAR(255) NOT NULL,
+    "type" VARCHAR(255) NOT NULL,
+    "isPrivate" BOOLEAN NOT NULL DEFAULT FALSE,
+    "tags" VARCHAR(255) [] NOT NULL DEFAULT '{}',
+    "dir" VARCHAR(255) NOT NULL,
+    "status" VARCHAR(255) NOT NULL DEFAULT 'open', -- open, finalized
+    "assetsId" UUID REFERENCES "assets"("id") ON DELETE SET NULL,
+    "createdAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    "updatedAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX ON "uploadSessions" ("uid");
+CREATE TABLE "uploadFiles" (
+    "uploadSessionsId" UUID REFERENCES "uploadSessions"("id") ON DELETE CASCADE NOT NULL,
+    "name" VARCHAR(255) NOT NULL,
+    "size" BIGINT NOT NULL,
+    "received" BIGINT NOT NULL DEFAULT 0,
+    "createdAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    "updatedAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("uploadSessionsId", "name")
+);
\ No newline at end of file
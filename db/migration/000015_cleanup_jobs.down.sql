@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "cleanupJobs";
\ No newline at end of file
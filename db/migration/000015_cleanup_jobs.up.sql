@@ -0,0 +1,16 @@
+CREATE TABLE "cleanupJobs" (
+    "id" UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "assetsId" UUID NOT NULL,
+    "uid" UUID NOT NULL,
+    "paths" TEXT [] NOT NULL,
+    "remaining" TEXT [] NOT NULL,
+    "status" VARCHAR(255) NOT NULL DEFAULT 'pending', -- pending, done, failed
+    "attempts" INT NOT NULL DEFAULT 0,
+    "lastError" TEXT,
+    "nextAttemptAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    "completedAt" TIMESTAMP WITH TIME ZONE,
+    "createdAt" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX ON "cleanupJobs" ("nextAttemptAt")
+WHERE "status" = 'pending';
+CREATE INDEX ON "cleanupJobs" ("status", "createdAt");
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE "users"
+ADD COLUMN IF NOT EXISTS "role" VARCHAR(255) NOT NULL DEFAULT 'user';
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE "cleanupJobs" DROP COLUMN IF EXISTS "orphaned";
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE "cleanupJobs"
+ADD COLUMN "orphaned" TEXT [] NOT NULL DEFAULT '{}';
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: CreateAssetStatusHistory :one
+INSERT INTO "assetStatusHistory" ("assetsId", "fromStatus", "toStatus")
+VALUES ($1, $2, $3)
+RETURNING *;
\ No newline at end of file
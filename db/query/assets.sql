@@ -54,6 +54,7 @@ SELECT a.*,
 FROM "assets" AS a
     LEFT JOIN "users" AS u ON u.uid = a.uid
 WHERE a.title LIKE '%' || $1 || '%' and a."isPrivate" = false
+    AND a.status <> 'draft'
 ORDER BY a."createdAt" DESC;
 -- name: GetAllAssetsWithLikesInformation :many
 SELECT a.*,
@@ -75,6 +76,7 @@ FROM "assets" AS a
     LEFT JOIN "likes" AS l ON l."assetsId" = a.id
     AND l.uid = $1
 WHERE a.title LIKE '%' || $2 || '%' and a."isPrivate" = false
+    AND a.status <> 'draft'
 ORDER BY a."createdAt" DESC;
 -- name: GetMyAssets :many
 SELECT a.*,
@@ -125,11 +127,18 @@ UPDATE "assets"
 SET "splatUrl" = $2
 WHERE id = $1
 RETURNING *;
--- name: UpdateAssetStatus :one
+-- name: TransitionAssetStatus :one
 UPDATE "assets"
-SET "status" = $3
-WHERE uid = $1
-    and id = $2
+SET "status" = $2,
+    "updatedAt" = now(),
+    "progressStage" = NULL,
+    "progressPercent" = NULL,
+    "progressIteration" = NULL,
+    "progressEtaSeconds" = NULL,
+    "progressPreviewUrl" = NULL,
+    "progressUpdatedAt" = NULL
+WHERE id = $1
+    AND "status" = $3
 RETURNING *;
 -- name: CheckIsLiked :one
 SELECT EXISTS (
@@ -155,4 +164,88 @@ RETURNING *;
 UPDATE "assets"
 SET likes = likes - 1
 WHERE "id" = $1
+RETURNING *;
+-- name: UpdateAssetFailure :one
+UPDATE "assets"
+SET "failureStage" = $2,
+    "failureReason" = $3,
+    "failureLogsUrl" = $4
+WHERE id = $1
+RETURNING *;
+-- name: ListStaleAssetsByStatus :many
+SELECT *
+FROM "assets"
+WHERE status = $1
+    AND "updatedAt" < $2
+ORDER BY "updatedAt" ASC;
+-- name: ResetAssetOutputs :one
+UPDATE "assets"
+SET "pclColmapUrl" = CASE
+        WHEN 'pclColmapUrl' = ANY($2::varchar []) THEN NULL
+        ELSE "pclColmapUrl"
+    END,
+    "splatUrl" = CASE
+        WHEN 'splatUrl' = ANY($2::varchar []) THEN NULL
+        ELSE "splatUrl"
+    END,
+    "segmentedPclDirUrl" = CASE
+        WHEN 'segmentedPclDirUrl' = ANY($2::varchar []) THEN NULL
+        ELSE "segmentedPclDirUrl"
+    END,
+    "segmentedSplatDirUrl" = CASE
+        WHEN 'segmentedSplatDirUrl' = ANY($2::varchar []) THEN NULL
+        ELSE "segmentedSplatDirUrl"
+    END,
+    "failureStage" = NULL,
+    "failureReason" = NULL,
+    "failureLogsUrl" = NULL
+WHERE id = $1
+RETURNING *;
+-- name: UpdateAssetProgress :one
+UPDATE "assets"
+SET "progressStage" = $2,
+    "progressPercent" = $3,
+    "progressIteration" = $4,
+    "progressEtaSeconds" = $5,
+    "progressPreviewUrl" = $6,
+    "progressUpdatedAt" = now()
+WHERE id = $1
+    AND status = $7
+RETURNING *;
+-- name: CountAssetsUsingPath :one
+SELECT COUNT(*)
+FROM (
+        SELECT rtrim(substring(url from '/files/.*$'), '/') AS path
+        FROM "assets",
+            unnest(ARRAY ["photoDirUrl", "pclUrl"]) AS url
+    ) AS used
+WHERE used.path = $1
+    OR starts_with(used.path, $1 || '/')
+    OR starts_with($1, used.path || '/');
+-- name: SetAssetOutput :one
+UPDATE "assets"
+SET "pclColmapUrl" = CASE
+        WHEN $2::varchar = 'pclColmapUrl' THEN $3
+        ELSE "pclColmapUrl"
+    END,
+    "splatUrl" = CASE
+        WHEN $2::varchar = 'splatUrl' THEN $3
+        ELSE "splatUrl"
+    END,
+    "segmentedPclDirUrl" = CASE
+        WHEN $2::varchar = 'segmentedPclDirUrl' THEN $3
+        ELSE "segmentedPclDirUrl"
+    END,
+    "segmentedSplatDirUrl" = CASE
+        WHEN $2::varchar = 'segmentedSplatDirUrl' THEN $3
+        ELSE "segmentedSplatDirUrl"
+    END
+WHERE id = $1
+RETURNING *;
+-- name: UpdateDraftAsset :one
+UPDATE "assets"
+SET "thumbnailUrl" = $2,
+    "pclUrl" = $3
+WHERE id = $1
+    AND status = 'draft'
 RETURNING *;
\ No newline at end of file
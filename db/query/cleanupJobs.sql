@@ -0,0 +1,38 @@
+-- name: CreateCleanupJob :one
+INSERT INTO "cleanupJobs" ("assetsId", uid, paths, remaining, orphaned)
+VALUES ($1, $2, $3, $3, $4)
+RETURNING *;
+-- name: ClaimCleanupJobs :many
+UPDATE "cleanupJobs"
+SET attempts = attempts + 1,
+    "nextAttemptAt" = $2
+WHERE id IN (
+        SELECT id
+        FROM "cleanupJobs"
+        WHERE status = 'pending'
+            AND "nextAttemptAt" <= NOW()
+        ORDER BY "createdAt" ASC
+        LIMIT $1 FOR
+        UPDATE SKIP LOCKED
+    )
+RETURNING *;
+-- name: MarkCleanupJobDone :exec
+UPDATE "cleanupJobs"
+SET status = 'done',
+    remaining = '{}',
+    "lastError" = NULL,
+    "completedAt" = NOW()
+WHERE id = $1;
+-- name: MarkCleanupJobFailed :exec
+UPDATE "cleanupJobs"
+SET status = $2,
+    remaining = $3,
+    "lastError" = $4,
+    "nextAttemptAt" = $5
+WHERE id = $1;
+-- name: ListCleanupJobsByStatus :many
+SELECT *
+FROM "cleanupJobs"
+WHERE status = $1
+ORDER BY "createdAt" DESC
+LIMIT $2;
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- name: CreateDeadLetter :one
+INSERT INTO "deadLetters" (
+        "queue",
+        "assetsId",
+        "reason",
+        "error",
+        "deathCount",
+        "payload"
+    )
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+-- name: GetDeadLetterById :one
+SELECT *
+FROM "deadLetters"
+WHERE id = $1
+LIMIT 1;
+-- name: ListDeadLetters :many
+SELECT *
+FROM "deadLetters"
+ORDER BY "createdAt" DESC
+LIMIT $1;
+-- name: ListDeadLettersByAsset :many
+SELECT *
+FROM "deadLetters"
+WHERE "assetsId" = $1
+ORDER BY "createdAt" DESC;
+-- name: DeleteDeadLetter :exec
+DELETE FROM "deadLetters"
+WHERE id = $1;
\ No newline at end of file
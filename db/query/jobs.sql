@@ -0,0 +1,99 @@
+-- name: CreateJob :one
+INSERT INTO "jobs" (id, "assetsId", stage, attempt)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+-- name: CountJobsByAssetAndStage :one
+SELECT COUNT(*)
+FROM "jobs"
+WHERE "assetsId" = $1
+    AND stage = $2;
+-- name: StartJob :one
+UPDATE "jobs"
+SET status = 'running',
+    "workerId" = $3,
+    "startedAt" = NOW()
+WHERE id = (
+        SELECT id
+        FROM "jobs"
+        WHERE "assetsId" = $1
+            AND stage = $2
+            AND "finishedAt" IS NULL
+        ORDER BY attempt DESC
+        LIMIT 1
+    )
+RETURNING *;
+-- name: FinishJob :one
+UPDATE "jobs"
+SET status = $3,
+    "workerId" = $4,
+    "resultUrl" = $5,
+    error = $6,
+    "finishedAt" = NOW()
+WHERE id = (
+        SELECT id
+        FROM "jobs"
+        WHERE "assetsId" = $1
+            AND stage = $2
+            AND "finishedAt" IS NULL
+        ORDER BY attempt DESC
+        LIMIT 1
+    )
+RETURNING *;
+-- name: CloseOpenJobs :exec
+UPDATE "jobs"
+SET status = $2,
+    error = $3,
+    "finishedAt" = NOW()
+WHERE "assetsId" = $1
+    AND "finishedAt" IS NULL;
+-- name: ListJobsByAsset :many
+SELECT *
+FROM "jobs"
+WHERE "assetsId" = $1
+ORDER BY "enqueuedAt" ASC;
+-- name: ListStageJobStats :many
+SELECT stage,
+    COUNT(*) FILTER (
+        WHERE status = 'queued'
+    ) AS queued,
+    COUNT(*) FILTER (
+        WHERE status = 'running'
+    ) AS running,
+    COUNT(*) FILTER (
+        WHERE status = 'succeeded'
+            AND "finishedAt" >= $1
+    ) AS succeeded
+FROM "jobs"
+WHERE "finishedAt" IS NULL
+    OR "finishedAt" >= $1
+GROUP BY stage
+ORDER BY stage;
+-- name: GetJobById :one
+SELECT *
+FROM "jobs"
+WHERE id = $1;
+-- name: FinishJobById :one
+UPDATE "jobs"
+SET status = $2,
+    "workerId" = $3,
+    "resultUrl" = $4,
+    error = $5,
+    "finishedAt" = NOW()
+WHERE id = $1
+    AND "finishedAt" IS NULL
+RETURNING *;
+-- name: CountStageRunJobs :one
+SELECT COUNT(*)
+FROM "jobs"
+WHERE "assetsId" = $1
+    AND stage = $2
+    AND "enqueuedAt" >= COALESCE(
+        (
+            SELECT MAX("createdAt")
+            FROM "assetStatusHistory"
+            WHERE "assetsId" = $1
+                AND "toStatus" = $3
+                AND "fromStatus" <> $3
+        ),
+        '-infinity'
+    );
\ No newline at end of file
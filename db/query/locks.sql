@@ -0,0 +1,2 @@
+-- name: TryAdvisoryXactLock :one
+SELECT pg_try_advisory_xact_lock($1);
\ No newline at end of file
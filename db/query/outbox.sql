@@ -0,0 +1,51 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO "outbox" ("queue", "payload", "exchange", "uid")
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+-- name: ClaimOutboxEvents :many
+UPDATE "outbox"
+SET attempts = attempts + 1,
+    "nextAttemptAt" = $2
+WHERE id IN (
+        SELECT id
+        FROM "outbox"
+        WHERE "sentAt" IS NULL
+            AND "nextAttemptAt" <= NOW()
+        ORDER BY "createdAt" ASC
+        LIMIT $1 FOR
+        UPDATE SKIP LOCKED
+    )
+RETURNING *;
+-- name: MarkOutboxEventSent :exec
+UPDATE "outbox"
+SET "sentAt" = NOW(),
+    "lastError" = NULL
+WHERE id = $1;
+-- name: MarkOutboxEventFailed :exec
+UPDATE "outbox"
+SET "lastError" = $2,
+    "nextAttemptAt" = $3
+WHERE id = $1;
+-- name: DeferOutboxEvent :exec
+UPDATE "outbox"
+SET attempts = attempts - 1,
+    "nextAttemptAt" = $2
+WHERE id = $1;
+-- name: CountInFlightJobsByUser :one
+SELECT GREATEST(
+        (
+            SELECT COUNT(*)
+            FROM "jobs"
+                JOIN "assets" ON "assets".id = "jobs"."assetsId"
+            WHERE "assets".uid = $1
+                AND "jobs"."finishedAt" IS NULL
+        ) - (
+            SELECT COUNT(*)
+            FROM "outbox"
+            WHERE "outbox".uid = $1
+                AND "outbox"."sentAt" IS NULL
+                AND "outbox".exchange = ''
+                AND "outbox".payload ? 'job_id'
+        ),
+        0
+    )::bigint AS "inFlight";
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- name: CreateProcessedCallback :one
+INSERT INTO "processedCallbacks" ("key", "assetsId", "workerId")
+VALUES ($1, $2, $3) ON CONFLICT ("key") DO NOTHING
+RETURNING *;
+-- name: DeleteProcessedCallback :exec
+DELETE FROM "processedCallbacks"
+WHERE "key" = $1;
\ No newline at end of file
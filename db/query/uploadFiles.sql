@@ -0,0 +1,26 @@
+-- name: CreateUploadFile :one
+INSERT INTO "uploadFiles" ("uploadSessionsId", name, size)
+VALUES ($1, $2, $3)
+RETURNING *;
+-- name: GetUploadFile :one
+SELECT *
+FROM "uploadFiles"
+WHERE "uploadSessionsId" = $1
+    AND name = $2;
+-- name: ListUploadFiles :many
+SELECT *
+FROM "uploadFiles"
+WHERE "uploadSessionsId" = $1
+ORDER BY name;
+-- name: AdvanceUploadFile :one
+UPDATE "uploadFiles"
+SET received = $3,
+    "updatedAt" = now()
+WHERE "uploadSessionsId" = $1
+    AND name = $2
+    AND received = $4
+RETURNING *;
+-- name: DeleteUploadFile :exec
+DELETE FROM "uploadFiles"
+WHERE "uploadSessionsId" = $1
+    AND name = $2;
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- name: CreateUploadSession :one
+INSERT INTO "uploadSessions" (
+        id,
+        uid,
+        title,
+        type,
+        "isPrivate",
+        tags,
+        dir,
+        "assetsId"
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+-- name: GetUploadSessionById :one
+SELECT *
+FROM "uploadSessions"
+WHERE id = $1;
+-- name: FinalizeUploadSession :one
+UPDATE "uploadSessions"
+SET status = 'finalized',
+    "updatedAt" = now()
+WHERE id = $1
+    AND status = 'open'
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,38 @@
+-- name: CreateWebhookDelivery :one
+INSERT INTO "webhookDeliveries" ("webhooksId", event, payload)
+VALUES ($1, $2, $3)
+RETURNING *;
+-- name: ClaimWebhookDeliveries :many
+UPDATE "webhookDeliveries"
+SET attempts = attempts + 1,
+    "nextAttemptAt" = $2
+WHERE id IN (
+        SELECT id
+        FROM "webhookDeliveries"
+        WHERE status = 'pending'
+            AND "nextAttemptAt" <= NOW()
+        ORDER BY "createdAt" ASC
+        LIMIT $1 FOR
+        UPDATE SKIP LOCKED
+    )
+RETURNING *;
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE "webhookDeliveries"
+SET status = 'delivered',
+    "responseStatus" = $2,
+    "lastError" = NULL,
+    "deliveredAt" = NOW()
+WHERE id = $1;
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE "webhookDeliveries"
+SET status = $2,
+    "responseStatus" = $3,
+    "lastError" = $4,
+    "nextAttemptAt" = $5
+WHERE id = $1;
+-- name: ListWebhookDeliveries :many
+SELECT *
+FROM "webhookDeliveries"
+WHERE "webhooksId" = $1
+ORDER BY "createdAt" DESC
+LIMIT $2;
\ No newline at end of file
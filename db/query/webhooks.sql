@@ -0,0 +1,31 @@
+-- name: CreateWebhook :one
+INSERT INTO "webhooks" (uid, url, secret, events)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+-- name: GetWebhookById :one
+SELECT *
+FROM "webhooks"
+WHERE id = $1
+LIMIT 1;
+-- name: ListWebhooksByUser :many
+SELECT *
+FROM "webhooks"
+WHERE uid = $1
+ORDER BY "createdAt" DESC;
+-- name: ListWebhooksForEvent :many
+SELECT *
+FROM "webhooks"
+WHERE uid = $1
+    AND "isActive" = TRUE
+    AND $2::varchar = ANY(events);
+-- name: UpdateWebhook :one
+UPDATE "webhooks"
+SET url = $2,
+    events = $3,
+    "isActive" = $4,
+    "updatedAt" = now()
+WHERE id = $1
+RETURNING *;
+-- name: DeleteWebhook :exec
+DELETE FROM "webhooks"
+WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: CreateWorkerCallback :one
+INSERT INTO "workerCallbacks" ("assetsId", "workerId", "endpoint", "url")
+VALUES ($1, $2, $3, $4)
+RETURNING *;
\ No newline at end of file
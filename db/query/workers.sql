@@ -0,0 +1,22 @@
+-- name: RegisterWorker :one
+INSERT INTO "workers" (id, stages, "gpuModel")
+VALUES ($1, $2, $3) ON CONFLICT (id) DO
+UPDATE
+SET stages = EXCLUDED.stages,
+    "gpuModel" = EXCLUDED."gpuModel",
+    "currentAssetsId" = NULL,
+    "currentStage" = NULL,
+    "registeredAt" = NOW(),
+    "lastSeenAt" = NOW()
+RETURNING *;
+-- name: HeartbeatWorker :one
+UPDATE "workers"
+SET "currentAssetsId" = $2,
+    "currentStage" = $3,
+    "lastSeenAt" = NOW()
+WHERE id = $1
+RETURNING *;
+-- name: ListWorkers :many
+SELECT *
+FROM "workers"
+ORDER BY "lastSeenAt" DESC;
\ No newline at end of file
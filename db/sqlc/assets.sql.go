@@ -35,6 +35,25 @@ func (q *Queries) CheckIsLiked(ctx context.Context, arg CheckIsLikedParams) (boo
 	return exists, err
 }
 
+const countAssetsUsingPath = `-- name: CountAssetsUsingPath :one
+SELECT COUNT(*)
+FROM (
+        SELECT rtrim(substring(url from '/files/.*$'), '/') AS path
+        FROM "assets",
+            unnest(ARRAY ["photoDirUrl", "pclUrl"]) AS url
+    ) AS used
+WHERE used.path = $1
+    OR starts_with(used.path, $1 || '/')
+    OR starts_with($1, used.path || '/')
+`
+
+func (q *Queries) CountAssetsUsingPath(ctx context.Context, path string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAssetsUsingPath, path)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAsset = `-- name: CreateAsset :one
 INSERT INTO "assets" (
         uid,
@@ -48,7 +67,7 @@ INSERT INTO "assets" (
         likes
     )
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 `
 
 type CreateAssetParams struct {
@@ -94,6 +113,15 @@ func (q *Queries) CreateAsset(ctx context.Context, arg CreateAssetParams) (Asset
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
@@ -117,7 +145,7 @@ const decreaseAssetLikes = `-- name: DecreaseAssetLikes :one
 UPDATE "assets"
 SET likes = likes - 1
 WHERE "id" = $1
-RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 `
 
 func (q *Queries) DecreaseAssetLikes(ctx context.Context, id uuid.UUID) (Assets, error) {
@@ -141,12 +169,21 @@ func (q *Queries) DecreaseAssetLikes(ctx context.Context, id uuid.UUID) (Assets,
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
 
 const getAllAssets = `-- name: GetAllAssets :many
-SELECT a.id, a.uid, a.title, a.slug, a.type, a."thumbnailUrl", a."photoDirUrl", a."splatUrl", a."pclUrl", a."pclColmapUrl", a."segmentedPclDirUrl", a."segmentedSplatDirUrl", a."isPrivate", a.status, a.likes, a."createdAt", a."updatedAt",
+SELECT a.id, a.uid, a.title, a.slug, a.type, a."thumbnailUrl", a."photoDirUrl", a."splatUrl", a."pclUrl", a."pclColmapUrl", a."segmentedPclDirUrl", a."segmentedSplatDirUrl", a."isPrivate", a.status, a.likes, a."createdAt", a."updatedAt", a."failureStage", a."failureReason", a."failureLogsUrl", a."progressStage", a."progressPercent", a."progressIteration", a."progressEtaSeconds", a."progressPreviewUrl", a."progressUpdatedAt",
     u.name,
     u.avatar,
     u.email
@@ -156,26 +193,35 @@ ORDER BY a."createdAt" DESC
 `
 
 type GetAllAssetsRow struct {
-	ID                   uuid.UUID      `json:"id"`
-	Uid                  uuid.UUID      `json:"uid"`
-	Title                string         `json:"title"`
-	Slug                 string         `json:"slug"`
-	Type                 string         `json:"type"`
-	ThumbnailUrl         string         `json:"thumbnailUrl"`
-	PhotoDirUrl          string         `json:"photoDirUrl"`
-	SplatUrl             sql.NullString `json:"splatUrl"`
-	PclUrl               sql.NullString `json:"pclUrl"`
-	PclColmapUrl         sql.NullString `json:"pclColmapUrl"`
-	SegmentedPclDirUrl   sql.NullString `json:"segmentedPclDirUrl"`
-	SegmentedSplatDirUrl sql.NullString `json:"segmentedSplatDirUrl"`
-	IsPrivate            bool           `json:"isPrivate"`
-	Status               string         `json:"status"`
-	Likes                int32          `json:"likes"`
-	CreatedAt            time.Time      `json:"createdAt"`
-	UpdatedAt            time.Time      `json:"updatedAt"`
-	Name                 sql.NullString `json:"name"`
-	Avatar               sql.NullString `json:"avatar"`
-	Email                sql.NullString `json:"email"`
+	ID                   uuid.UUID       `json:"id"`
+	Uid                  uuid.UUID       `json:"uid"`
+	Title                string          `json:"title"`
+	Slug                 string          `json:"slug"`
+	Type                 string          `json:"type"`
+	ThumbnailUrl         string          `json:"thumbnailUrl"`
+	PhotoDirUrl          string          `json:"photoDirUrl"`
+	SplatUrl             sql.NullString  `json:"splatUrl"`
+	PclUrl               sql.NullString  `json:"pclUrl"`
+	PclColmapUrl         sql.NullString  `json:"pclColmapUrl"`
+	SegmentedPclDirUrl   sql.NullString  `json:"segmentedPclDirUrl"`
+	SegmentedSplatDirUrl sql.NullString  `json:"segmentedSplatDirUrl"`
+	IsPrivate            bool            `json:"isPrivate"`
+	Status               string          `json:"status"`
+	Likes                int32           `json:"likes"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	UpdatedAt            time.Time       `json:"updatedAt"`
+	FailureStage         sql.NullString  `json:"failureStage"`
+	FailureReason        sql.NullString  `json:"failureReason"`
+	FailureLogsUrl       sql.NullString  `json:"failureLogsUrl"`
+	ProgressStage        sql.NullString  `json:"progressStage"`
+	ProgressPercent      sql.NullFloat64 `json:"progressPercent"`
+	ProgressIteration    sql.NullInt32   `json:"progressIteration"`
+	ProgressEtaSeconds   sql.NullInt32   `json:"progressEtaSeconds"`
+	ProgressPreviewUrl   sql.NullString  `json:"progressPreviewUrl"`
+	ProgressUpdatedAt    sql.NullTime    `json:"progressUpdatedAt"`
+	Name                 sql.NullString  `json:"name"`
+	Avatar               sql.NullString  `json:"avatar"`
+	Email                sql.NullString  `json:"email"`
 }
 
 func (q *Queries) GetAllAssets(ctx context.Context) ([]GetAllAssetsRow, error) {
@@ -205,6 +251,15 @@ func (q *Queries) GetAllAssets(ctx context.Context) ([]GetAllAssetsRow, error) {
 			&i.Likes,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.FailureStage,
+			&i.FailureReason,
+			&i.FailureLogsUrl,
+			&i.ProgressStage,
+			&i.ProgressPercent,
+			&i.ProgressIteration,
+			&i.ProgressEtaSeconds,
+			&i.ProgressPreviewUrl,
+			&i.ProgressUpdatedAt,
 			&i.Name,
 			&i.Avatar,
 			&i.Email,
@@ -223,7 +278,7 @@ func (q *Queries) GetAllAssets(ctx context.Context) ([]GetAllAssetsRow, error) {
 }
 
 const getAllAssetsByKeyword = `-- name: GetAllAssetsByKeyword :many
-SELECT a.id, a.uid, a.title, a.slug, a.type, a."thumbnailUrl", a."photoDirUrl", a."splatUrl", a."pclUrl", a."pclColmapUrl", a."segmentedPclDirUrl", a."segmentedSplatDirUrl", a."isPrivate", a.status, a.likes, a."createdAt", a."updatedAt",
+SELECT a.id, a.uid, a.title, a.slug, a.type, a."thumbnailUrl", a."photoDirUrl", a."splatUrl", a."pclUrl", a."pclColmapUrl", a."segmentedPclDirUrl", a."segmentedSplatDirUrl", a."isPrivate", a.status, a.likes, a."createdAt", a."updatedAt", a."failureStage", a."failureReason", a."failureLogsUrl", a."progressStage", a."progressPercent", a."progressIteration", a."progressEtaSeconds", a."progressPreviewUrl", a."progressUpdatedAt",
     u.name,
     u.avatar,
     u.email,
@@ -236,31 +291,41 @@ SELECT a.id, a.uid, a.title, a.slug, a.type, a."thumbnailUrl", a."photoDirUrl",
 FROM "assets" AS a
     LEFT JOIN "users" AS u ON u.uid = a.uid
 WHERE a.title LIKE '%' || $1 || '%' and a."isPrivate" = false
+    AND a.status <> 'draft'
 ORDER BY a."createdAt" DESC
 `
 
 type GetAllAssetsByKeywordRow struct {
-	ID                   uuid.UUID      `json:"id"`
-	Uid                  uuid.UUID      `json:"uid"`
-	Title                string         `json:"title"`
-	Slug                 string         `json:"slug"`
-	Type                 string         `json:"type"`
-	ThumbnailUrl         string         `json:"thumbnailUrl"`
-	PhotoDirUrl          string         `json:"photoDirUrl"`
-	SplatUrl             sql.NullString `json:"splatUrl"`
-	PclUrl               sql.NullString `json:"pclUrl"`
-	PclColmapUrl         sql.NullString `json:"pclColmapUrl"`
-	SegmentedPclDirUrl   sql.NullString `json:"segmentedPclDirUrl"`
-	SegmentedSplatDirUrl sql.NullString `json:"segmentedSplatDirUrl"`
-	IsPrivate            bool           `json:"isPrivate"`
-	Status               string         `json:"status"`
-	Likes                int32          `json:"likes"`
-	CreatedAt            time.Time      `json:"createdAt"`
-	UpdatedAt            time.Time      `json:"updatedAt"`
-	Name                 sql.NullString `json:"name"`
-	Avatar               sql.NullString `json:"avatar"`
-	Email                sql.NullString `json:"email"`
-	TagNames             []string       `json:"tag_names"`
+	ID                   uuid.UUID       `json:"id"`
+	Uid                  uuid.UUID       `json:"uid"`
+	Title                string          `json:"title"`
+	Slug                 string          `json:"slug"`
+	Type                 string          `json:"type"`
+	ThumbnailUrl         string          `json:"thumbnailUrl"`
+	PhotoDirUrl          string          `json:"photoDirUrl"`
+	SplatUrl             sql.NullString  `json:"splatUrl"`
+	PclUrl               sql.NullString  `json:"pclUrl"`
+	PclColmapUrl         sql.NullString  `json:"pclColmapUrl"`
+	SegmentedPclDirUrl   sql.NullString  `json:"segmentedPclDirUrl"`
+	SegmentedSplatDirUrl sql.NullString  `json:"segmentedSplatDirUrl"`
+	IsPrivate            bool            `json:"isPrivate"`
+	Status               string          `json:"status"`
+	Likes                int32           `json:"likes"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	UpdatedAt            time.Time       `json:"updatedAt"`
+	FailureStage         sql.NullString  `json:"failureStage"`
+	FailureReason        sql.NullString  `json:"failureReason"`
+	FailureLogsUrl       sql.NullString  `json:"failureLogsUrl"`
+	ProgressStage        sql.NullString  `json:"progressStage"`
+	ProgressPercent      sql.NullFloat64 `json:"progressPercent"`
+	ProgressIteration    sql.NullInt32   `json:"progressIteration"`
+	ProgressEtaSeconds   sql.NullInt32   `json:"progressEtaSeconds"`
+	ProgressPreviewUrl   sql.NullString  `json:"progressPreviewUrl"`
+	ProgressUpdatedAt    sql.NullTime    `json:"progressUpdatedAt"`
+	Name                 sql.NullString  `json:"name"`
+	Avatar               sql.NullString  `json:"avatar"`
+	Email                sql.NullString  `json:"email"`
+	TagNames             []string        `json:"tag_names"`
 }
 
 func (q *Queries) GetAllAssetsByKeyword(ctx context.Context, dollar_1 sql.NullString) ([]GetAllAssetsByKeywordRow, error) {
@@ -290,6 +355,15 @@ func (q *Queries) GetAllAssetsByKeyword(ctx context.Context, dollar_1 sql.NullSt
 			&i.Likes,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.FailureStage,
+			&i.FailureReason,
+			&i.FailureLogsUrl,
+			&i.ProgressStage,
+			&i.ProgressPercent,
+			&i.ProgressIteration,
+			&i.ProgressEtaSeconds,
+			&i.ProgressPreviewUrl,
+			&i.ProgressUpdatedAt,
 			&i.Name,
 			&i.Avatar,
 			&i.Email,
@@ -309,7 +383,7 @@ func (q *Queries) GetAllAssetsByKeyword(ctx context.Context, dollar_1 sql.NullSt
 }
 
 const getAllAssetsWithLikesInformation = `-- name: GetAllAssetsWithLikesInformation :many
-SELECT a.id, a.uid, a.title, a.slug, a.type, a."thumbnailUrl", a."photoDirUrl", a."splatUrl", a."pclUrl", a."pclColmapUrl", a."segmentedPclDirUrl", a."segmentedSplatDirUrl", a."isPrivate", a.status, a.likes, a."createdAt", a."updatedAt",
+SELECT a.id, a.uid, a.title, a.slug, a.type, a."thumbnailUrl", a."photoDirUrl", a."splatUrl", a."pclUrl", a."pclColmapUrl", a."segmentedPclDirUrl", a."segmentedSplatDirUrl", a."isPrivate", a.status, a.likes, a."createdAt", a."updatedAt", a."failureStage", a."failureReason", a."failureLogsUrl", a."progressStage", a."progressPercent", a."progressIteration", a."progressEtaSeconds", a."progressPreviewUrl", a."progressUpdatedAt",
     u.name,
     u.avatar,
     u.email,
@@ -328,6 +402,7 @@ FROM "assets" AS a
     LEFT JOIN "likes" AS l ON l."assetsId" = a.id
     AND l.uid = $1
 WHERE a.title LIKE '%' || $2 || '%' and a."isPrivate" = false
+    AND a.status <> 'draft'
 ORDER BY a."createdAt" DESC
 `
 
@@ -337,28 +412,37 @@ type GetAllAssetsWithLikesInformationParams struct {
 }
 
 type GetAllAssetsWithLikesInformationRow struct {
-	ID                   uuid.UUID      `json:"id"`
-	Uid                  uuid.UUID      `json:"uid"`
-	Title                string         `json:"title"`
-	Slug                 string         `json:"slug"`
-	Type                 string         `json:"type"`
-	ThumbnailUrl         string         `json:"thumbnailUrl"`
-	PhotoDirUrl          string         `json:"photoDirUrl"`
-	SplatUrl             sql.NullString `json:"splatUrl"`
-	PclUrl               sql.NullString `json:"pclUrl"`
-	PclColmapUrl         sql.NullString `json:"pclColmapUrl"`
-	SegmentedPclDirUrl   sql.NullString `json:"segmentedPclDirUrl"`
-	SegmentedSplatDirUrl sql.NullString `json:"segmentedSplatDirUrl"`
-	IsPrivate            bool           `json:"isPrivate"`
-	Status               string         `json:"status"`
-	Likes                int32          `json:"likes"`
-	CreatedAt            time.Time      `json:"createdAt"`
-	UpdatedAt            time.Time      `json:"updatedAt"`
-	Name                 sql.NullString `json:"name"`
-	Avatar               sql.NullString `json:"avatar"`
-	Email                sql.NullString `json:"email"`
-	IsLikedByMe          bool           `json:"isLikedByMe"`
-	TagNames             []string       `json:"tag_names"`
+	ID                   uuid.UUID       `json:"id"`
+	Uid                  uuid.UUID       `json:"uid"`
+	Title                string          `json:"title"`
+	Slug                 string          `json:"slug"`
+	Type                 string          `json:"type"`
+	ThumbnailUrl         string          `json:"thumbnailUrl"`
+	PhotoDirUrl          string          `json:"photoDirUrl"`
+	SplatUrl             sql.NullString  `json:"splatUrl"`
+	PclUrl               sql.NullString  `json:"pclUrl"`
+	PclColmapUrl         sql.NullString  `json:"pclColmapUrl"`
+	SegmentedPclDirUrl   sql.NullString  `json:"segmentedPclDirUrl"`
+	SegmentedSplatDirUrl sql.NullString  `json:"segmentedSplatDirUrl"`
+	IsPrivate            bool            `json:"isPrivate"`
+	Status               string          `json:"status"`
+	Likes                int32           `json:"likes"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	UpdatedAt            time.Time       `json:"updatedAt"`
+	FailureStage         sql.NullString  `json:"failureStage"`
+	FailureReason        sql.NullString  `json:"failureReason"`
+	FailureLogsUrl       sql.NullString  `json:"failureLogsUrl"`
+	ProgressStage        sql.NullString  `json:"progressStage"`
+	ProgressPercent      sql.NullFloat64 `json:"progressPercent"`
+	ProgressIteration    sql.NullInt32   `json:"progressIteration"`
+	ProgressEtaSeconds   sql.NullInt32   `json:"progressEtaSeconds"`
+	ProgressPreviewUrl   sql.NullString  `json:"progressPreviewUrl"`
+	ProgressUpdatedAt    sql.NullTime    `json:"progressUpdatedAt"`
+	Name                 sql.NullString  `json:"name"`
+	Avatar               sql.NullString  `json:"avatar"`
+	Email                sql.NullString  `json:"email"`
+	IsLikedByMe          bool            `json:"isLikedByMe"`
+	TagNames             []string        `json:"tag_names"`
 }
 
 func (q *Queries) GetAllAssetsWithLikesInformation(ctx context.Context, arg GetAllAssetsWithLikesInformationParams) ([]GetAllAssetsWithLikesInformationRow, error) {
@@ -388,6 +472,15 @@ func (q *Queries) GetAllAssetsWithLikesInformation(ctx context.Context, arg GetA
 			&i.Likes,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.FailureStage,
+			&i.FailureReason,
+			&i.FailureLogsUrl,
+			&i.ProgressStage,
+			&i.ProgressPercent,
+			&i.ProgressIteration,
+			&i.ProgressEtaSeconds,
+			&i.ProgressPreviewUrl,
+			&i.ProgressUpdatedAt,
 			&i.Name,
 			&i.Avatar,
 			&i.Email,
@@ -408,7 +501,7 @@ func (q *Queries) GetAllAssetsWithLikesInformation(ctx context.Context, arg GetA
 }
 
 const getAssetsById = `-- name: GetAssetsById :one
-SELECT id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+SELECT id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 FROM "assets"
 WHERE id = $1
 LIMIT 1
@@ -435,12 +528,21 @@ func (q *Queries) GetAssetsById(ctx context.Context, id uuid.UUID) (Assets, erro
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
 
 const getAssetsBySlug = `-- name: GetAssetsBySlug :one
-SELECT id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+SELECT id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 FROM "assets"
 WHERE slug = $1
 LIMIT 1
@@ -467,12 +569,21 @@ func (q *Queries) GetAssetsBySlug(ctx context.Context, slug string) (Assets, err
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
 
 const getAssetsByUid = `-- name: GetAssetsByUid :many
-SELECT id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+SELECT id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 FROM "assets"
 WHERE uid = $1
 ORDER BY "createdAt" DESC
@@ -505,6 +616,15 @@ func (q *Queries) GetAssetsByUid(ctx context.Context, uid uuid.UUID) ([]Assets,
 			&i.Likes,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.FailureStage,
+			&i.FailureReason,
+			&i.FailureLogsUrl,
+			&i.ProgressStage,
+			&i.ProgressPercent,
+			&i.ProgressIteration,
+			&i.ProgressEtaSeconds,
+			&i.ProgressPreviewUrl,
+			&i.ProgressUpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -520,7 +640,7 @@ func (q *Queries) GetAssetsByUid(ctx context.Context, uid uuid.UUID) ([]Assets,
 }
 
 const getMyAssets = `-- name: GetMyAssets :many
-SELECT a.id, a.uid, a.title, a.slug, a.type, a."thumbnailUrl", a."photoDirUrl", a."splatUrl", a."pclUrl", a."pclColmapUrl", a."segmentedPclDirUrl", a."segmentedSplatDirUrl", a."isPrivate", a.status, a.likes, a."createdAt", a."updatedAt",
+SELECT a.id, a.uid, a.title, a.slug, a.type, a."thumbnailUrl", a."photoDirUrl", a."splatUrl", a."pclUrl", a."pclColmapUrl", a."segmentedPclDirUrl", a."segmentedSplatDirUrl", a."isPrivate", a.status, a.likes, a."createdAt", a."updatedAt", a."failureStage", a."failureReason", a."failureLogsUrl", a."progressStage", a."progressPercent", a."progressIteration", a."progressEtaSeconds", a."progressPreviewUrl", a."progressUpdatedAt",
     CASE
         WHEN l.uid = $1 THEN TRUE
         ELSE FALSE
@@ -545,25 +665,34 @@ type GetMyAssetsParams struct {
 }
 
 type GetMyAssetsRow struct {
-	ID                   uuid.UUID      `json:"id"`
-	Uid                  uuid.UUID      `json:"uid"`
-	Title                string         `json:"title"`
-	Slug                 string         `json:"slug"`
-	Type                 string         `json:"type"`
-	ThumbnailUrl         string         `json:"thumbnailUrl"`
-	PhotoDirUrl          string         `json:"photoDirUrl"`
-	SplatUrl             sql.NullString `json:"splatUrl"`
-	PclUrl               sql.NullString `json:"pclUrl"`
-	PclColmapUrl         sql.NullString `json:"pclColmapUrl"`
-	SegmentedPclDirUrl   sql.NullString `json:"segmentedPclDirUrl"`
-	SegmentedSplatDirUrl sql.NullString `json:"segmentedSplatDirUrl"`
-	IsPrivate            bool           `json:"isPrivate"`
-	Status               string         `json:"status"`
-	Likes                int32          `json:"likes"`
-	CreatedAt            time.Time      `json:"createdAt"`
-	UpdatedAt            time.Time      `json:"updatedAt"`
-	IsLikedByMe          sql.NullBool   `json:"isLikedByMe"`
-	TagNames             []string       `json:"tag_names"`
+	ID                   uuid.UUID       `json:"id"`
+	Uid                  uuid.UUID       `json:"uid"`
+	Title                string          `json:"title"`
+	Slug                 string          `json:"slug"`
+	Type                 string          `json:"type"`
+	ThumbnailUrl         string          `json:"thumbnailUrl"`
+	PhotoDirUrl          string          `json:"photoDirUrl"`
+	SplatUrl             sql.NullString  `json:"splatUrl"`
+	PclUrl               sql.NullString  `json:"pclUrl"`
+	PclColmapUrl         sql.NullString  `json:"pclColmapUrl"`
+	SegmentedPclDirUrl   sql.NullString  `json:"segmentedPclDirUrl"`
+	SegmentedSplatDirUrl sql.NullString  `json:"segmentedSplatDirUrl"`
+	IsPrivate            bool            `json:"isPrivate"`
+	Status               string          `json:"status"`
+	Likes                int32           `json:"likes"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	UpdatedAt            time.Time       `json:"updatedAt"`
+	FailureStage         sql.NullString  `json:"failureStage"`
+	FailureReason        sql.NullString  `json:"failureReason"`
+	FailureLogsUrl       sql.NullString  `json:"failureLogsUrl"`
+	ProgressStage        sql.NullString  `json:"progressStage"`
+	ProgressPercent      sql.NullFloat64 `json:"progressPercent"`
+	ProgressIteration    sql.NullInt32   `json:"progressIteration"`
+	ProgressEtaSeconds   sql.NullInt32   `json:"progressEtaSeconds"`
+	ProgressPreviewUrl   sql.NullString  `json:"progressPreviewUrl"`
+	ProgressUpdatedAt    sql.NullTime    `json:"progressUpdatedAt"`
+	IsLikedByMe          sql.NullBool    `json:"isLikedByMe"`
+	TagNames             []string        `json:"tag_names"`
 }
 
 func (q *Queries) GetMyAssets(ctx context.Context, arg GetMyAssetsParams) ([]GetMyAssetsRow, error) {
@@ -593,6 +722,15 @@ func (q *Queries) GetMyAssets(ctx context.Context, arg GetMyAssetsParams) ([]Get
 			&i.Likes,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.FailureStage,
+			&i.FailureReason,
+			&i.FailureLogsUrl,
+			&i.ProgressStage,
+			&i.ProgressPercent,
+			&i.ProgressIteration,
+			&i.ProgressEtaSeconds,
+			&i.ProgressPreviewUrl,
+			&i.ProgressUpdatedAt,
 			&i.IsLikedByMe,
 			pq.Array(&i.TagNames),
 		); err != nil {
@@ -643,7 +781,7 @@ const increaseAssetLikes = `-- name: IncreaseAssetLikes :one
 UPDATE "assets"
 SET likes = likes + 1
 WHERE "id" = $1
-RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 `
 
 func (q *Queries) IncreaseAssetLikes(ctx context.Context, id uuid.UUID) (Assets, error) {
@@ -667,15 +805,87 @@ func (q *Queries) IncreaseAssetLikes(ctx context.Context, id uuid.UUID) (Assets,
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
 
+const listStaleAssetsByStatus = `-- name: ListStaleAssetsByStatus :many
+SELECT id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
+FROM "assets"
+WHERE status = $1
+    AND "updatedAt" < $2
+ORDER BY "updatedAt" ASC
+`
+
+type ListStaleAssetsByStatusParams struct {
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (q *Queries) ListStaleAssetsByStatus(ctx context.Context, arg ListStaleAssetsByStatusParams) ([]Assets, error) {
+	rows, err := q.db.QueryContext(ctx, listStaleAssetsByStatus, arg.Status, arg.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Assets{}
+	for rows.Next() {
+		var i Assets
+		if err := rows.Scan(
+			&i.ID,
+			&i.Uid,
+			&i.Title,
+			&i.Slug,
+			&i.Type,
+			&i.ThumbnailUrl,
+			&i.PhotoDirUrl,
+			&i.SplatUrl,
+			&i.PclUrl,
+			&i.PclColmapUrl,
+			&i.SegmentedPclDirUrl,
+			&i.SegmentedSplatDirUrl,
+			&i.IsPrivate,
+			&i.Status,
+			&i.Likes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FailureStage,
+			&i.FailureReason,
+			&i.FailureLogsUrl,
+			&i.ProgressStage,
+			&i.ProgressPercent,
+			&i.ProgressIteration,
+			&i.ProgressEtaSeconds,
+			&i.ProgressPreviewUrl,
+			&i.ProgressUpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const removeAsset = `-- name: RemoveAsset :one
 DELETE FROM "assets"
 WHERE uid = $1
     AND id = $2
-RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 `
 
 type RemoveAssetParams struct {
@@ -704,6 +914,15 @@ func (q *Queries) RemoveAsset(ctx context.Context, arg RemoveAssetParams) (Asset
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
@@ -732,22 +951,324 @@ func (q *Queries) RemoveLike(ctx context.Context, arg RemoveLikeParams) (Likes,
 	return i, err
 }
 
-const updateAssetStatus = `-- name: UpdateAssetStatus :one
+const resetAssetOutputs = `-- name: ResetAssetOutputs :one
 UPDATE "assets"
-SET "status" = $3
-WHERE uid = $1
-    and id = $2
-RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+SET "pclColmapUrl" = CASE
+        WHEN 'pclColmapUrl' = ANY($2::varchar []) THEN NULL
+        ELSE "pclColmapUrl"
+    END,
+    "splatUrl" = CASE
+        WHEN 'splatUrl' = ANY($2::varchar []) THEN NULL
+        ELSE "splatUrl"
+    END,
+    "segmentedPclDirUrl" = CASE
+        WHEN 'segmentedPclDirUrl' = ANY($2::varchar []) THEN NULL
+        ELSE "segmentedPclDirUrl"
+    END,
+    "segmentedSplatDirUrl" = CASE
+        WHEN 'segmentedSplatDirUrl' = ANY($2::varchar []) THEN NULL
+        ELSE "segmentedSplatDirUrl"
+    END,
+    "failureStage" = NULL,
+    "failureReason" = NULL,
+    "failureLogsUrl" = NULL
+WHERE id = $1
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
+`
+
+type ResetAssetOutputsParams struct {
+	ID      uuid.UUID `json:"id"`
+	Column2 []string  `json:"column_2"`
+}
+
+func (q *Queries) ResetAssetOutputs(ctx context.Context, arg ResetAssetOutputsParams) (Assets, error) {
+	row := q.db.QueryRowContext(ctx, resetAssetOutputs, arg.ID, pq.Array(arg.Column2))
+	var i Assets
+	err := row.Scan(
+		&i.ID,
+		&i.Uid,
+		&i.Title,
+		&i.Slug,
+		&i.Type,
+		&i.ThumbnailUrl,
+		&i.PhotoDirUrl,
+		&i.SplatUrl,
+		&i.PclUrl,
+		&i.PclColmapUrl,
+		&i.SegmentedPclDirUrl,
+		&i.SegmentedSplatDirUrl,
+		&i.IsPrivate,
+		&i.Status,
+		&i.Likes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
+	)
+	return i, err
+}
+
+const setAssetOutput = `-- name: SetAssetOutput :one
+UPDATE "assets"
+SET "pclColmapUrl" = CASE
+        WHEN $2::varchar = 'pclColmapUrl' THEN $3
+        ELSE "pclColmapUrl"
+    END,
+    "splatUrl" = CASE
+        WHEN $2::varchar = 'splatUrl' THEN $3
+        ELSE "splatUrl"
+    END,
+    "segmentedPclDirUrl" = CASE
+        WHEN $2::varchar = 'segmentedPclDirUrl' THEN $3
+        ELSE "segmentedPclDirUrl"
+    END,
+    "segmentedSplatDirUrl" = CASE
+        WHEN $2::varchar = 'segmentedSplatDirUrl' THEN $3
+        ELSE "segmentedSplatDirUrl"
+    END
+WHERE id = $1
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 `
 
-type UpdateAssetStatusParams struct {
-	Uid    uuid.UUID `json:"uid"`
-	ID     uuid.UUID `json:"id"`
-	Status string    `json:"status"`
+type SetAssetOutputParams struct {
+	ID     uuid.UUID      `json:"id"`
+	Output string         `json:"output"`
+	Url    sql.NullString `json:"url"`
+}
+
+func (q *Queries) SetAssetOutput(ctx context.Context, arg SetAssetOutputParams) (Assets, error) {
+	row := q.db.QueryRowContext(ctx, setAssetOutput, arg.ID, arg.Output, arg.Url)
+	var i Assets
+	err := row.Scan(
+		&i.ID,
+		&i.Uid,
+		&i.Title,
+		&i.Slug,
+		&i.Type,
+		&i.ThumbnailUrl,
+		&i.PhotoDirUrl,
+		&i.SplatUrl,
+		&i.PclUrl,
+		&i.PclColmapUrl,
+		&i.SegmentedPclDirUrl,
+		&i.SegmentedSplatDirUrl,
+		&i.IsPrivate,
+		&i.Status,
+		&i.Likes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
+	)
+	return i, err
+}
+
+const transitionAssetStatus = `-- name: TransitionAssetStatus :one
+UPDATE "assets"
+SET "status" = $2,
+    "updatedAt" = now(),
+    "progressStage" = NULL,
+    "progressPercent" = NULL,
+    "progressIteration" = NULL,
+    "progressEtaSeconds" = NULL,
+    "progressPreviewUrl" = NULL,
+    "progressUpdatedAt" = NULL
+WHERE id = $1
+    AND "status" = $3
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
+`
+
+type TransitionAssetStatusParams struct {
+	ID       uuid.UUID `json:"id"`
+	Status   string    `json:"status"`
+	Status_2 string    `json:"status_2"`
+}
+
+func (q *Queries) TransitionAssetStatus(ctx context.Context, arg TransitionAssetStatusParams) (Assets, error) {
+	row := q.db.QueryRowContext(ctx, transitionAssetStatus, arg.ID, arg.Status, arg.Status_2)
+	var i Assets
+	err := row.Scan(
+		&i.ID,
+		&i.Uid,
+		&i.Title,
+		&i.Slug,
+		&i.Type,
+		&i.ThumbnailUrl,
+		&i.PhotoDirUrl,
+		&i.SplatUrl,
+		&i.PclUrl,
+		&i.PclColmapUrl,
+		&i.SegmentedPclDirUrl,
+		&i.SegmentedSplatDirUrl,
+		&i.IsPrivate,
+		&i.Status,
+		&i.Likes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
+	)
+	return i, err
+}
+
+const updateAssetFailure = `-- name: UpdateAssetFailure :one
+UPDATE "assets"
+SET "failureStage" = $2,
+    "failureReason" = $3,
+    "failureLogsUrl" = $4
+WHERE id = $1
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
+`
+
+type UpdateAssetFailureParams struct {
+	ID             uuid.UUID      `json:"id"`
+	FailureStage   sql.NullString `json:"failureStage"`
+	FailureReason  sql.NullString `json:"failureReason"`
+	FailureLogsUrl sql.NullString `json:"failureLogsUrl"`
+}
+
+func (q *Queries) UpdateAssetFailure(ctx context.Context, arg UpdateAssetFailureParams) (Assets, error) {
+	row := q.db.QueryRowContext(ctx, updateAssetFailure,
+		arg.ID,
+		arg.FailureStage,
+		arg.FailureReason,
+		arg.FailureLogsUrl,
+	)
+	var i Assets
+	err := row.Scan(
+		&i.ID,
+		&i.Uid,
+		&i.Title,
+		&i.Slug,
+		&i.Type,
+		&i.ThumbnailUrl,
+		&i.PhotoDirUrl,
+		&i.SplatUrl,
+		&i.PclUrl,
+		&i.PclColmapUrl,
+		&i.SegmentedPclDirUrl,
+		&i.SegmentedSplatDirUrl,
+		&i.IsPrivate,
+		&i.Status,
+		&i.Likes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
+	)
+	return i, err
+}
+
+const updateAssetProgress = `-- name: UpdateAssetProgress :one
+UPDATE "assets"
+SET "progressStage" = $2,
+    "progressPercent" = $3,
+    "progressIteration" = $4,
+    "progressEtaSeconds" = $5,
+    "progressPreviewUrl" = $6,
+    "progressUpdatedAt" = now()
+WHERE id = $1
+    AND status = $7
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
+`
+
+type UpdateAssetProgressParams struct {
+	ID                 uuid.UUID       `json:"id"`
+	ProgressStage      sql.NullString  `json:"progressStage"`
+	ProgressPercent    sql.NullFloat64 `json:"progressPercent"`
+	ProgressIteration  sql.NullInt32   `json:"progressIteration"`
+	ProgressEtaSeconds sql.NullInt32   `json:"progressEtaSeconds"`
+	ProgressPreviewUrl sql.NullString  `json:"progressPreviewUrl"`
+	Status             string          `json:"status"`
+}
+
+func (q *Queries) UpdateAssetProgress(ctx context.Context, arg UpdateAssetProgressParams) (Assets, error) {
+	row := q.db.QueryRowContext(ctx, updateAssetProgress,
+		arg.ID,
+		arg.ProgressStage,
+		arg.ProgressPercent,
+		arg.ProgressIteration,
+		arg.ProgressEtaSeconds,
+		arg.ProgressPreviewUrl,
+		arg.Status,
+	)
+	var i Assets
+	err := row.Scan(
+		&i.ID,
+		&i.Uid,
+		&i.Title,
+		&i.Slug,
+		&i.Type,
+		&i.ThumbnailUrl,
+		&i.PhotoDirUrl,
+		&i.SplatUrl,
+		&i.PclUrl,
+		&i.PclColmapUrl,
+		&i.SegmentedPclDirUrl,
+		&i.SegmentedSplatDirUrl,
+		&i.IsPrivate,
+		&i.Status,
+		&i.Likes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
+	)
+	return i, err
+}
+
+const updateDraftAsset = `-- name: UpdateDraftAsset :one
+UPDATE "assets"
+SET "thumbnailUrl" = $2,
+    "pclUrl" = $3
+WHERE id = $1
+    AND status = 'draft'
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
+`
+
+type UpdateDraftAssetParams struct {
+	ID           uuid.UUID      `json:"id"`
+	ThumbnailUrl string         `json:"thumbnailUrl"`
+	PclUrl       sql.NullString `json:"pclUrl"`
 }
 
-func (q *Queries) UpdateAssetStatus(ctx context.Context, arg UpdateAssetStatusParams) (Assets, error) {
-	row := q.db.QueryRowContext(ctx, updateAssetStatus, arg.Uid, arg.ID, arg.Status)
+func (q *Queries) UpdateDraftAsset(ctx context.Context, arg UpdateDraftAssetParams) (Assets, error) {
+	row := q.db.QueryRowContext(ctx, updateDraftAsset, arg.ID, arg.ThumbnailUrl, arg.PclUrl)
 	var i Assets
 	err := row.Scan(
 		&i.ID,
@@ -767,6 +1288,15 @@ func (q *Queries) UpdateAssetStatus(ctx context.Context, arg UpdateAssetStatusPa
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
@@ -775,7 +1305,7 @@ const updatePTvUrl = `-- name: UpdatePTvUrl :one
 UPDATE "assets"
 SET "segmentedPclDirUrl" = $2
 WHERE id = $1
-RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 `
 
 type UpdatePTvUrlParams struct {
@@ -804,6 +1334,15 @@ func (q *Queries) UpdatePTvUrl(ctx context.Context, arg UpdatePTvUrlParams) (Ass
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
@@ -812,7 +1351,7 @@ const updatePointCloudUrlFromColmap = `-- name: UpdatePointCloudUrlFromColmap :o
 UPDATE "assets"
 SET "pclColmapUrl" = $2
 WHERE id = $1
-RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 `
 
 type UpdatePointCloudUrlFromColmapParams struct {
@@ -841,6 +1380,15 @@ func (q *Queries) UpdatePointCloudUrlFromColmap(ctx context.Context, arg UpdateP
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
@@ -850,7 +1398,7 @@ UPDATE "assets"
 SET "pclUrl" = $3
 WHERE uid = $1
     and id = $2
-RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 `
 
 type UpdatePointCloudUrlFromLidarParams struct {
@@ -880,6 +1428,15 @@ func (q *Queries) UpdatePointCloudUrlFromLidar(ctx context.Context, arg UpdatePo
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
@@ -888,7 +1445,7 @@ const updateSagaUrl = `-- name: UpdateSagaUrl :one
 UPDATE "assets"
 SET "segmentedSplatDirUrl" = $2
 WHERE id = $1
-RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 `
 
 type UpdateSagaUrlParams struct {
@@ -917,6 +1474,15 @@ func (q *Queries) UpdateSagaUrl(ctx context.Context, arg UpdateSagaUrlParams) (A
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
@@ -925,7 +1491,7 @@ const updateSplatUrl = `-- name: UpdateSplatUrl :one
 UPDATE "assets"
 SET "splatUrl" = $2
 WHERE id = $1
-RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt"
+RETURNING id, uid, title, slug, type, "thumbnailUrl", "photoDirUrl", "splatUrl", "pclUrl", "pclColmapUrl", "segmentedPclDirUrl", "segmentedSplatDirUrl", "isPrivate", status, likes, "createdAt", "updatedAt", "failureStage", "failureReason", "failureLogsUrl", "progressStage", "progressPercent", "progressIteration", "progressEtaSeconds", "progressPreviewUrl", "progressUpdatedAt"
 `
 
 type UpdateSplatUrlParams struct {
@@ -954,6 +1520,15 @@ func (q *Queries) UpdateSplatUrl(ctx context.Context, arg UpdateSplatUrlParams)
 		&i.Likes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FailureStage,
+		&i.FailureReason,
+		&i.FailureLogsUrl,
+		&i.ProgressStage,
+		&i.ProgressPercent,
+		&i.ProgressIteration,
+		&i.ProgressEtaSeconds,
+		&i.ProgressPreviewUrl,
+		&i.ProgressUpdatedAt,
 	)
 	return i, err
 }
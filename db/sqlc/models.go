@@ -6,29 +6,47 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AssetStatusHistory struct {
+	ID         uuid.UUID `json:"id"`
+	AssetsId   uuid.UUID `json:"assetsId"`
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
 type Assets struct {
-	ID                   uuid.UUID      `json:"id"`
-	Uid                  uuid.UUID      `json:"uid"`
-	Title                string         `json:"title"`
-	Slug                 string         `json:"slug"`
-	Type                 string         `json:"type"`
-	ThumbnailUrl         string         `json:"thumbnailUrl"`
-	PhotoDirUrl          string         `json:"photoDirUrl"`
-	SplatUrl             sql.NullString `json:"splatUrl"`
-	PclUrl               sql.NullString `json:"pclUrl"`
-	PclColmapUrl         sql.NullString `json:"pclColmapUrl"`
-	SegmentedPclDirUrl   sql.NullString `json:"segmentedPclDirUrl"`
-	SegmentedSplatDirUrl sql.NullString `json:"segmentedSplatDirUrl"`
-	IsPrivate            bool           `json:"isPrivate"`
-	Status               string         `json:"status"`
-	Likes                int32          `json:"likes"`
-	CreatedAt            time.Time      `json:"createdAt"`
-	UpdatedAt            time.Time      `json:"updatedAt"`
+	ID                   uuid.UUID       `json:"id"`
+	Uid                  uuid.UUID       `json:"uid"`
+	Title                string          `json:"title"`
+	Slug                 string          `json:"slug"`
+	Type                 string          `json:"type"`
+	ThumbnailUrl         string          `json:"thumbnailUrl"`
+	PhotoDirUrl          string          `json:"photoDirUrl"`
+	SplatUrl             sql.NullString  `json:"splatUrl"`
+	PclUrl               sql.NullString  `json:"pclUrl"`
+	PclColmapUrl         sql.NullString  `json:"pclColmapUrl"`
+	SegmentedPclDirUrl   sql.NullString  `json:"segmentedPclDirUrl"`
+	SegmentedSplatDirUrl sql.NullString  `json:"segmentedSplatDirUrl"`
+	IsPrivate            bool            `json:"isPrivate"`
+	Status               string          `json:"status"`
+	Likes                int32           `json:"likes"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	UpdatedAt            time.Time       `json:"updatedAt"`
+	FailureStage         sql.NullString  `json:"failureStage"`
+	FailureReason        sql.NullString  `json:"failureReason"`
+	FailureLogsUrl       sql.NullString  `json:"failureLogsUrl"`
+	ProgressStage        sql.NullString  `json:"progressStage"`
+	ProgressPercent      sql.NullFloat64 `json:"progressPercent"`
+	ProgressIteration    sql.NullInt32   `json:"progressIteration"`
+	ProgressEtaSeconds   sql.NullInt32   `json:"progressEtaSeconds"`
+	ProgressPreviewUrl   sql.NullString  `json:"progressPreviewUrl"`
+	ProgressUpdatedAt    sql.NullTime    `json:"progressUpdatedAt"`
 }
 
 type AssetsToTags struct {
@@ -38,6 +56,46 @@ type AssetsToTags struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+type CleanupJobs struct {
+	ID            uuid.UUID      `json:"id"`
+	AssetsId      uuid.UUID      `json:"assetsId"`
+	Uid           uuid.UUID      `json:"uid"`
+	Paths         []string       `json:"paths"`
+	Remaining     []string       `json:"remaining"`
+	Status        string         `json:"status"`
+	Attempts      int32          `json:"attempts"`
+	LastError     sql.NullString `json:"lastError"`
+	NextAttemptAt time.Time      `json:"nextAttemptAt"`
+	CompletedAt   sql.NullTime   `json:"completedAt"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	Orphaned      []string       `json:"orphaned"`
+}
+
+type DeadLetters struct {
+	ID         uuid.UUID      `json:"id"`
+	Queue      string         `json:"queue"`
+	AssetsId   uuid.NullUUID  `json:"assetsId"`
+	Reason     string         `json:"reason"`
+	Error      sql.NullString `json:"error"`
+	DeathCount int32          `json:"deathCount"`
+	Payload    string         `json:"payload"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+type Jobs struct {
+	ID         uuid.UUID      `json:"id"`
+	AssetsId   uuid.UUID      `json:"assetsId"`
+	Stage      string         `json:"stage"`
+	Attempt    int32          `json:"attempt"`
+	Status     string         `json:"status"`
+	WorkerId   sql.NullString `json:"workerId"`
+	ResultUrl  sql.NullString `json:"resultUrl"`
+	Error      sql.NullString `json:"error"`
+	EnqueuedAt time.Time      `json:"enqueuedAt"`
+	StartedAt  sql.NullTime   `json:"startedAt"`
+	FinishedAt sql.NullTime   `json:"finishedAt"`
+}
+
 type Likes struct {
 	Uid       uuid.UUID `json:"uid"`
 	AssetsId  uuid.UUID `json:"assetsId"`
@@ -45,6 +103,26 @@ type Likes struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+type Outbox struct {
+	ID            uuid.UUID       `json:"id"`
+	Queue         string          `json:"queue"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int32           `json:"attempts"`
+	LastError     sql.NullString  `json:"lastError"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	SentAt        sql.NullTime    `json:"sentAt"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	Exchange      string          `json:"exchange"`
+	Uid           uuid.NullUUID   `json:"uid"`
+}
+
+type ProcessedCallbacks struct {
+	Key       string    `json:"key"`
+	AssetsId  uuid.UUID `json:"assetsId"`
+	WorkerId  string    `json:"workerId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type Tags struct {
 	ID        uuid.UUID `json:"id"`
 	Name      string    `json:"name"`
@@ -53,6 +131,29 @@ type Tags struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+type UploadFiles struct {
+	UploadSessionsId uuid.UUID `json:"uploadSessionsId"`
+	Name             string    `json:"name"`
+	Size             int64     `json:"size"`
+	Received         int64     `json:"received"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+type UploadSessions struct {
+	ID        uuid.UUID     `json:"id"`
+	Uid       uuid.UUID     `json:"uid"`
+	Title     string        `json:"title"`
+	Type      string        `json:"type"`
+	IsPrivate bool          `json:"isPrivate"`
+	Tags      []string      `json:"tags"`
+	Dir       string        `json:"dir"`
+	Status    string        `json:"status"`
+	AssetsId  uuid.NullUUID `json:"assetsId"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
 type Users struct {
 	Uid               uuid.UUID      `json:"uid"`
 	Name              sql.NullString `json:"name"`
@@ -63,4 +164,49 @@ type Users struct {
 	CreatedAt         time.Time      `json:"createdAt"`
 	UpdatedAt         time.Time      `json:"updatedAt"`
 	PasswordChangedAt time.Time      `json:"passwordChangedAt"`
+	Role              string         `json:"role"`
+}
+
+type WebhookDeliveries struct {
+	ID             uuid.UUID       `json:"id"`
+	WebhooksId     uuid.UUID       `json:"webhooksId"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus sql.NullInt32   `json:"responseStatus"`
+	LastError      sql.NullString  `json:"lastError"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	DeliveredAt    sql.NullTime    `json:"deliveredAt"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+type Webhooks struct {
+	ID        uuid.UUID `json:"id"`
+	Uid       uuid.UUID `json:"uid"`
+	Url       string    `json:"url"`
+	Secret    string    `json:"secret"`
+	Events    []string  `json:"events"`
+	IsActive  bool      `json:"isActive"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WorkerCallbacks struct {
+	ID        uuid.UUID `json:"id"`
+	AssetsId  uuid.UUID `json:"assetsId"`
+	WorkerId  string    `json:"workerId"`
+	Endpoint  string    `json:"endpoint"`
+	Url       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Workers struct {
+	ID              string         `json:"id"`
+	Stages          []string       `json:"stages"`
+	GpuModel        sql.NullString `json:"gpuModel"`
+	CurrentAssetsId uuid.NullUUID  `json:"currentAssetsId"`
+	CurrentStage    sql.NullString `json:"currentStage"`
+	RegisteredAt    time.Time      `json:"registeredAt"`
+	LastSeenAt      time.Time      `json:"lastSeenAt"`
 }
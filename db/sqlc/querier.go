@@ -12,29 +12,88 @@ import (
 )
 
 type Querier interface {
+	AdvanceUploadFile(ctx context.Context, arg AdvanceUploadFileParams) (UploadFiles, error)
 	CheckIsLiked(ctx context.Context, arg CheckIsLikedParams) (bool, error)
+	ClaimCleanupJobs(ctx context.Context, arg ClaimCleanupJobsParams) ([]CleanupJobs, error)
+	ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]Outbox, error)
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]WebhookDeliveries, error)
+	CloseOpenJobs(ctx context.Context, arg CloseOpenJobsParams) error
+	CountAssetsUsingPath(ctx context.Context, path string) (int64, error)
+	CountInFlightJobsByUser(ctx context.Context, uid uuid.UUID) (int64, error)
+	CountJobsByAssetAndStage(ctx context.Context, arg CountJobsByAssetAndStageParams) (int64, error)
+	CountStageRunJobs(ctx context.Context, arg CountStageRunJobsParams) (int64, error)
 	CreateAsset(ctx context.Context, arg CreateAssetParams) (Assets, error)
+	CreateAssetStatusHistory(ctx context.Context, arg CreateAssetStatusHistoryParams) (AssetStatusHistory, error)
 	CreateAssetsToTags(ctx context.Context, arg CreateAssetsToTagsParams) (AssetsToTags, error)
+	CreateCleanupJob(ctx context.Context, arg CreateCleanupJobParams) (CleanupJobs, error)
+	CreateDeadLetter(ctx context.Context, arg CreateDeadLetterParams) (DeadLetters, error)
+	CreateJob(ctx context.Context, arg CreateJobParams) (Jobs, error)
 	CreateLike(ctx context.Context, arg CreateLikeParams) error
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreateProcessedCallback(ctx context.Context, arg CreateProcessedCallbackParams) (ProcessedCallbacks, error)
 	CreateTag(ctx context.Context, arg CreateTagParams) (Tags, error)
+	CreateUploadFile(ctx context.Context, arg CreateUploadFileParams) (UploadFiles, error)
+	CreateUploadSession(ctx context.Context, arg CreateUploadSessionParams) (UploadSessions, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (Users, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhooks, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDeliveries, error)
+	CreateWorkerCallback(ctx context.Context, arg CreateWorkerCallbackParams) (WorkerCallbacks, error)
 	DecreaseAssetLikes(ctx context.Context, id uuid.UUID) (Assets, error)
+	DeferOutboxEvent(ctx context.Context, arg DeferOutboxEventParams) error
+	DeleteDeadLetter(ctx context.Context, id uuid.UUID) error
+	DeleteProcessedCallback(ctx context.Context, key string) error
+	DeleteUploadFile(ctx context.Context, arg DeleteUploadFileParams) error
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	FinalizeUploadSession(ctx context.Context, id uuid.UUID) (UploadSessions, error)
+	FinishJob(ctx context.Context, arg FinishJobParams) (Jobs, error)
+	FinishJobById(ctx context.Context, arg FinishJobByIdParams) (Jobs, error)
 	GetAllAssets(ctx context.Context) ([]GetAllAssetsRow, error)
 	GetAllAssetsByKeyword(ctx context.Context, dollar_1 sql.NullString) ([]GetAllAssetsByKeywordRow, error)
 	GetAllAssetsWithLikesInformation(ctx context.Context, arg GetAllAssetsWithLikesInformationParams) ([]GetAllAssetsWithLikesInformationRow, error)
 	GetAssetsById(ctx context.Context, id uuid.UUID) (Assets, error)
 	GetAssetsBySlug(ctx context.Context, slug string) (Assets, error)
 	GetAssetsByUid(ctx context.Context, uid uuid.UUID) ([]Assets, error)
+	GetDeadLetterById(ctx context.Context, id uuid.UUID) (DeadLetters, error)
+	GetJobById(ctx context.Context, id uuid.UUID) (Jobs, error)
 	GetMyAssets(ctx context.Context, arg GetMyAssetsParams) ([]GetMyAssetsRow, error)
 	GetSlug(ctx context.Context, slug string) ([]string, error)
 	GetTagsByKeyword(ctx context.Context, arg GetTagsByKeywordParams) ([]Tags, error)
 	GetTagsByTagsName(ctx context.Context, name []string) ([]Tags, error)
+	GetUploadFile(ctx context.Context, arg GetUploadFileParams) (UploadFiles, error)
+	GetUploadSessionById(ctx context.Context, id uuid.UUID) (UploadSessions, error)
 	GetUserByEmail(ctx context.Context, email string) (Users, error)
 	GetUserById(ctx context.Context, uid uuid.UUID) (Users, error)
+	GetWebhookById(ctx context.Context, id uuid.UUID) (Webhooks, error)
+	HeartbeatWorker(ctx context.Context, arg HeartbeatWorkerParams) (Workers, error)
 	IncreaseAssetLikes(ctx context.Context, id uuid.UUID) (Assets, error)
+	ListCleanupJobsByStatus(ctx context.Context, arg ListCleanupJobsByStatusParams) ([]CleanupJobs, error)
+	ListDeadLetters(ctx context.Context, limit int32) ([]DeadLetters, error)
+	ListDeadLettersByAsset(ctx context.Context, assetsId uuid.NullUUID) ([]DeadLetters, error)
+	ListJobsByAsset(ctx context.Context, assetsId uuid.UUID) ([]Jobs, error)
+	ListStageJobStats(ctx context.Context, finishedAt sql.NullTime) ([]ListStageJobStatsRow, error)
+	ListStaleAssetsByStatus(ctx context.Context, arg ListStaleAssetsByStatusParams) ([]Assets, error)
+	ListUploadFiles(ctx context.Context, uploadSessionsId uuid.UUID) ([]UploadFiles, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDeliveries, error)
+	ListWebhooksByUser(ctx context.Context, uid uuid.UUID) ([]Webhooks, error)
+	ListWebhooksForEvent(ctx context.Context, arg ListWebhooksForEventParams) ([]Webhooks, error)
+	ListWorkers(ctx context.Context) ([]Workers, error)
+	MarkCleanupJobDone(ctx context.Context, id uuid.UUID) error
+	MarkCleanupJobFailed(ctx context.Context, arg MarkCleanupJobFailedParams) error
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventSent(ctx context.Context, id uuid.UUID) error
+	MarkWebhookDeliveryDelivered(ctx context.Context, arg MarkWebhookDeliveryDeliveredParams) error
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error
+	RegisterWorker(ctx context.Context, arg RegisterWorkerParams) (Workers, error)
 	RemoveAsset(ctx context.Context, arg RemoveAssetParams) (Assets, error)
 	RemoveLike(ctx context.Context, arg RemoveLikeParams) (Likes, error)
-	UpdateAssetStatus(ctx context.Context, arg UpdateAssetStatusParams) (Assets, error)
+	ResetAssetOutputs(ctx context.Context, arg ResetAssetOutputsParams) (Assets, error)
+	SetAssetOutput(ctx context.Context, arg SetAssetOutputParams) (Assets, error)
+	StartJob(ctx context.Context, arg StartJobParams) (Jobs, error)
+	TransitionAssetStatus(ctx context.Context, arg TransitionAssetStatusParams) (Assets, error)
+	TryAdvisoryXactLock(ctx context.Context, pgTryAdvisoryXactLock int64) (bool, error)
+	UpdateAssetFailure(ctx context.Context, arg UpdateAssetFailureParams) (Assets, error)
+	UpdateAssetProgress(ctx context.Context, arg UpdateAssetProgressParams) (Assets, error)
+	UpdateDraftAsset(ctx context.Context, arg UpdateDraftAssetParams) (Assets, error)
 	UpdatePTvUrl(ctx context.Context, arg UpdatePTvUrlParams) (Assets, error)
 	UpdatePointCloudUrlFromColmap(ctx context.Context, arg UpdatePointCloudUrlFromColmapParams) (Assets, error)
 	UpdatePointCloudUrlFromLidar(ctx context.Context, arg UpdatePointCloudUrlFromLidarParams) (Assets, error)
@@ -42,6 +101,7 @@ type Querier interface {
 	UpdateSplatUrl(ctx context.Context, arg UpdateSplatUrlParams) (Assets, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (Users, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (Users, error)
+	UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhooks, error)
 }
 
 var _ Querier = (*Queries)(nil)
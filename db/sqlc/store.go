@@ -1,11 +1,19 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 )
 
 type Store interface {
 	Querier
+	CreateAssetTx(ctx context.Context, arg CreateAssetTxParams) (CreateAssetTxResult, error)
+	CreateUploadSessionTx(ctx context.Context, arg CreateUploadSessionTxParams) (CreateUploadSessionTxResult, error)
+	RemoveAssetTx(ctx context.Context, arg RemoveAssetTxParams) (RemoveAssetTxResult, error)
+	StartDraftAssetTx(ctx context.Context, arg StartDraftAssetTxParams) (Assets, error)
+	TransitionAssetTx(ctx context.Context, arg TransitionAssetTxParams) (Assets, error)
+	WithAdvisoryLock(ctx context.Context, key int64, fn func() error) (bool, error)
 }
 
 type SQLStore struct {
@@ -19,3 +27,42 @@ func NewStore(db *sql.DB) Store {
 		Queries: New(db),
 	}
 }
+
+// execTx runs fn within a database transaction and commits it when fn
+// succeeds.
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// WithAdvisoryLock runs fn while holding the transaction level advisory lock
+// key, so only one process runs it at a time. It reports false without
+// running fn when another process holds the lock.
+func (store *SQLStore) WithAdvisoryLock(ctx context.Context, key int64, fn func() error) (bool, error) {
+	locked := false
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		locked, err = q.TryAdvisoryXactLock(ctx, key)
+		if err != nil || !locked {
+			return err
+		}
+
+		return fn()
+	})
+
+	return locked, err
+}
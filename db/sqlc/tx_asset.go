@@ -0,0 +1,379 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/segment3d-app/segment3d-be/util"
+)
+
+type CreateAssetTxParams struct {
+	CreateAssetParams
+	PclUrl sql.NullString
+	Tags   []string
+	// Event builds the outbox message that starts processing the asset.
+	Event func(asset Assets) (CreateOutboxEventParams, error)
+	// Status is the status the asset moves to once its event is queued.
+	Status string
+	// Stage is the pipeline stage the event starts. A job is opened for it.
+	Stage string
+	// JobID is the id of the job opened for Stage, which the event carries
+	// so the worker can report back against it.
+	JobID uuid.UUID
+	// Notify queues the webhook deliveries of the change when set. It runs
+	// within the transaction, so the deliveries are recorded exactly when the
+	// change is committed.
+	Notify func(ctx context.Context, q Querier, asset Assets) error
+}
+
+type CreateAssetTxResult struct {
+	Asset Assets `json:"asset"`
+	Tags  []Tags `json:"tags"`
+}
+
+// CreateAssetTx creates the asset with its tags and queues the event that
+// starts processing it in the outbox, all within one transaction.
+func (store *SQLStore) CreateAssetTx(ctx context.Context, arg CreateAssetTxParams) (CreateAssetTxResult, error) {
+	var result CreateAssetTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Asset, err = q.CreateAsset(ctx, arg.CreateAssetParams)
+		if err != nil {
+			return err
+		}
+
+		if arg.PclUrl.Valid {
+			result.Asset, err = q.UpdatePointCloudUrlFromLidar(ctx, UpdatePointCloudUrlFromLidarParams{
+				Uid:    arg.Uid,
+				ID:     result.Asset.ID,
+				PclUrl: arg.PclUrl,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Tags, err = createAssetTags(ctx, q, result.Asset.ID, arg.Tags)
+		if err != nil {
+			return err
+		}
+
+		event, err := arg.Event(result.Asset)
+		if err != nil {
+			return err
+		}
+
+		result.Asset, err = transitionAsset(ctx, q, TransitionAssetTxParams{
+			ID:           result.Asset.ID,
+			FromStatus:   result.Asset.Status,
+			ToStatus:     arg.Status,
+			Event:        &event,
+			EnqueueStage: arg.Stage,
+			JobID:        arg.JobID,
+			Notify:       arg.Notify,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+func createAssetTags(ctx context.Context, q *Queries, assetID uuid.UUID, names []string) ([]Tags, error) {
+	tags, err := q.GetTagsByTagsName(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	allTags := tags
+	for _, name := range names {
+		exists := false
+		for _, tag := range tags {
+			if name == tag.Name {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+
+		tag, err := q.CreateTag(ctx, CreateTagParams{
+			Name: name,
+			Slug: util.GenerateBaseSlug(name),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		allTags = append(allTags, tag)
+	}
+
+	for _, tag := range allTags {
+		_, err := q.CreateAssetsToTags(ctx, CreateAssetsToTagsParams{
+			AssetsId: assetID,
+			TagsId:   tag.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return allTags, nil
+}
+
+// ErrJobFinished is returned by TransitionAssetTx when the job it was asked to
+// finish is no longer open, e.g. because a late result of a previous attempt
+// arrived after the job was closed.
+var ErrJobFinished = errors.New("job is no longer open")
+
+type TransitionAssetTxParams struct {
+	ID         uuid.UUID
+	FromStatus string
+	ToStatus   string
+	// ResetOutputs clears the outputs of a previous run when set, so a
+	// reprocess starts from a clean asset.
+	ResetOutputs *ResetAssetOutputsParams
+	// Failure stores why the asset failed when set. It is only written if
+	// the asset was still in FromStatus.
+	Failure *UpdateAssetFailureParams
+	// Output stores the url of a stage result in the asset column it names
+	// when set.
+	Output *SetAssetOutputParams
+	// WorkerCallback records the worker that delivered the result when set.
+	WorkerCallback *CreateWorkerCallbackParams
+	// FinishJob closes the open job of the stage when set. Assets enqueued
+	// before jobs were recorded have none, which is not an error.
+	FinishJob *FinishJobParams
+	// FinishJobID narrows FinishJob down to the given job. ErrJobFinished is
+	// returned if that job is no longer open.
+	FinishJobID uuid.NullUUID
+	// Event is queued in the outbox together with the status change when set.
+	Event *CreateOutboxEventParams
+	// EventFor builds an event from the asset once its output is stored when
+	// set, such as the message starting the next stage, which carries the
+	// output of the one that just finished.
+	EventFor func(asset Assets) (CreateOutboxEventParams, error)
+	// CloseJobs ends the open jobs of the asset when set.
+	CloseJobs *CloseOpenJobsParams
+	// EnqueueStage opens a job for the pipeline stage the asset enters when
+	// set.
+	EnqueueStage string
+	// JobID is the id of the job opened for EnqueueStage. A new one is
+	// generated when it is not set.
+	JobID uuid.UUID
+	// Notify queues the webhook deliveries of the change when set. It runs
+	// within the transaction, so the deliveries are recorded exactly when the
+	// change is committed.
+	Notify func(ctx context.Context, q Querier, asset Assets) error
+}
+
+// TransitionAssetTx moves the asset from FromStatus to ToStatus, records the
+// change in the status history, resets the outputs of a previous run, stores
+// the optional failure or stage output, finishes the reporting job, queues the
+// optional events in the outbox, closes and opens the jobs of the asset and
+// queues its webhooks as requested. sql.ErrNoRows is returned when the asset
+// is no longer in FromStatus.
+func (store *SQLStore) TransitionAssetTx(ctx context.Context, arg TransitionAssetTxParams) (Assets, error) {
+	var asset Assets
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		asset, err = transitionAsset(ctx, q, arg)
+		return err
+	})
+
+	return asset, err
+}
+
+func transitionAsset(ctx context.Context, q *Queries, arg TransitionAssetTxParams) (Assets, error) {
+	asset, err := q.TransitionAssetStatus(ctx, TransitionAssetStatusParams{
+		ID:       arg.ID,
+		Status:   arg.ToStatus,
+		Status_2: arg.FromStatus,
+	})
+	if err != nil {
+		return asset, err
+	}
+
+	_, err = q.CreateAssetStatusHistory(ctx, CreateAssetStatusHistoryParams{
+		AssetsId:   arg.ID,
+		FromStatus: arg.FromStatus,
+		ToStatus:   arg.ToStatus,
+	})
+	if err != nil {
+		return asset, err
+	}
+
+	if arg.ResetOutputs != nil {
+		asset, err = q.ResetAssetOutputs(ctx, *arg.ResetOutputs)
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	if arg.Failure != nil {
+		asset, err = q.UpdateAssetFailure(ctx, *arg.Failure)
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	if arg.Output != nil {
+		asset, err = q.SetAssetOutput(ctx, *arg.Output)
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	if arg.WorkerCallback != nil {
+		_, err = q.CreateWorkerCallback(ctx, *arg.WorkerCallback)
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	if arg.FinishJob != nil {
+		err = finishStageJob(ctx, q, *arg.FinishJob, arg.FinishJobID)
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	if arg.Event != nil {
+		_, err = q.CreateOutboxEvent(ctx, *arg.Event)
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	if arg.EventFor != nil {
+		event, err := arg.EventFor(asset)
+		if err != nil {
+			return asset, err
+		}
+
+		_, err = q.CreateOutboxEvent(ctx, event)
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	if arg.CloseJobs != nil {
+		err = q.CloseOpenJobs(ctx, *arg.CloseJobs)
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	if len(arg.EnqueueStage) > 0 {
+		attempts, err := q.CountJobsByAssetAndStage(ctx, CountJobsByAssetAndStageParams{
+			AssetsId: arg.ID,
+			Stage:    arg.EnqueueStage,
+		})
+		if err != nil {
+			return asset, err
+		}
+
+		jobID := arg.JobID
+		if jobID == uuid.Nil {
+			jobID = uuid.New()
+		}
+
+		_, err = q.CreateJob(ctx, CreateJobParams{
+			ID:       jobID,
+			AssetsId: arg.ID,
+			Stage:    arg.EnqueueStage,
+			Attempt:  int32(attempts) + 1,
+		})
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	if arg.Notify != nil {
+		err = arg.Notify(ctx, q, asset)
+		if err != nil {
+			return asset, err
+		}
+	}
+
+	return asset, nil
+}
+
+func finishStageJob(ctx context.Context, q *Queries, arg FinishJobParams, jobID uuid.NullUUID) error {
+	if !jobID.Valid {
+		_, err := q.FinishJob(ctx, arg)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	_, err := q.FinishJobById(ctx, FinishJobByIdParams{
+		ID:        jobID.UUID,
+		Status:    arg.Status,
+		WorkerId:  arg.WorkerId,
+		ResultUrl: arg.ResultUrl,
+		Error:     arg.Error,
+	})
+	if err == sql.ErrNoRows {
+		return ErrJobFinished
+	}
+
+	return err
+}
+
+type RemoveAssetTxParams struct {
+	RemoveAssetParams
+	// Paths lists the storage paths of the removed asset and its jobs, which
+	// a cleanup job is queued for, and the ones it points at but does not
+	// own, which are reported as orphaned rather than deleted.
+	Paths func(asset Assets, jobs []Jobs) (paths []string, orphaned []string)
+	// Notify queues the webhook deliveries of the removal within the
+	// transaction when set.
+	Notify func(ctx context.Context, q Querier, asset Assets) error
+}
+
+type RemoveAssetTxResult struct {
+	Asset      Assets      `json:"asset"`
+	CleanupJob CleanupJobs `json:"cleanupJob"`
+}
+
+// RemoveAssetTx deletes the asset and queues the cleanup of its files in the
+// same transaction, so the files are not forgotten if the server stops right
+// after the asset is gone.
+func (store *SQLStore) RemoveAssetTx(ctx context.Context, arg RemoveAssetTxParams) (RemoveAssetTxResult, error) {
+	var result RemoveAssetTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// the jobs go with the asset, so their outputs are collected first
+		jobs, err := q.ListJobsByAsset(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Asset, err = q.RemoveAsset(ctx, arg.RemoveAssetParams)
+		if err != nil {
+			return err
+		}
+
+		paths, orphaned := arg.Paths(result.Asset, jobs)
+		result.CleanupJob, err = q.CreateCleanupJob(ctx, CreateCleanupJobParams{
+			AssetsId: result.Asset.ID,
+			Uid:      result.Asset.Uid,
+			Paths:    paths,
+			Orphaned: orphaned,
+		})
+		if err != nil || arg.Notify == nil {
+			return err
+		}
+
+		return arg.Notify(ctx, q, result.Asset)
+	})
+
+	return result, err
+}
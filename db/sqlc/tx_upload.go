@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type CreateUploadSessionTxParams struct {
+	CreateUploadSessionParams
+	// Asset is the draft asset the upload is tied to. It is created along
+	// with the session and starts processing once the session is finalized.
+	Asset CreateAssetParams
+	// Files are the files the client declared it will upload.
+	Files []CreateUploadFileParams
+}
+
+type CreateUploadSessionTxResult struct {
+	Session UploadSessions `json:"session"`
+	Asset   Assets         `json:"asset"`
+	Tags    []Tags         `json:"tags"`
+	Files   []UploadFiles  `json:"files"`
+}
+
+// CreateUploadSessionTx creates the draft asset and opens the upload session
+// for its photos with their declared files in one transaction.
+func (store *SQLStore) CreateUploadSessionTx(ctx context.Context, arg CreateUploadSessionTxParams) (CreateUploadSessionTxResult, error) {
+	result := CreateUploadSessionTxResult{Files: []UploadFiles{}}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Asset, err = q.CreateAsset(ctx, arg.Asset)
+		if err != nil {
+			return err
+		}
+
+		result.Tags, err = createAssetTags(ctx, q, result.Asset.ID, arg.Tags)
+		if err != nil {
+			return err
+		}
+
+		arg.AssetsId = uuid.NullUUID{UUID: result.Asset.ID, Valid: true}
+		result.Session, err = q.CreateUploadSession(ctx, arg.CreateUploadSessionParams)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range arg.Files {
+			file.UploadSessionsId = result.Session.ID
+			created, err := q.CreateUploadFile(ctx, file)
+			if err != nil {
+				return err
+			}
+			result.Files = append(result.Files, created)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+type StartDraftAssetTxParams struct {
+	// UploadSessionID is the upload session that is finalized. The draft
+	// asset it was created with is started.
+	UploadSessionID uuid.UUID
+	ThumbnailUrl    string
+	PclUrl          sql.NullString
+	// Event builds the outbox message that starts processing the asset.
+	Event func(asset Assets) (CreateOutboxEventParams, error)
+	// Status is the status the asset moves to once its event is queued.
+	Status string
+	// Stage is the pipeline stage the event starts. A job is opened for it.
+	Stage string
+	// JobID is the id of the job opened for Stage.
+	JobID uuid.UUID
+	// Notify queues the webhook deliveries of the change when set. It runs
+	// within the transaction, so the deliveries are recorded exactly when the
+	// change is committed.
+	Notify func(ctx context.Context, q Querier, asset Assets) error
+}
+
+// StartDraftAssetTx finalizes the upload session and moves its draft asset
+// into the first stage of its pipeline, all within one transaction.
+// sql.ErrNoRows is returned when the session was finalized already or its
+// asset is no longer a draft.
+func (store *SQLStore) StartDraftAssetTx(ctx context.Context, arg StartDraftAssetTxParams) (Assets, error) {
+	var asset Assets
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		session, err := q.FinalizeUploadSession(ctx, arg.UploadSessionID)
+		if err != nil {
+			return err
+		}
+		if !session.AssetsId.Valid {
+			return sql.ErrNoRows
+		}
+
+		asset, err = q.UpdateDraftAsset(ctx, UpdateDraftAssetParams{
+			ID:           session.AssetsId.UUID,
+			ThumbnailUrl: arg.ThumbnailUrl,
+			PclUrl:       arg.PclUrl,
+		})
+		if err != nil {
+			return err
+		}
+
+		event, err := arg.Event(asset)
+		if err != nil {
+			return err
+		}
+
+		asset, err = transitionAsset(ctx, q, TransitionAssetTxParams{
+			ID:           asset.ID,
+			FromStatus:   asset.Status,
+			ToStatus:     arg.Status,
+			Event:        &event,
+			EnqueueStage: arg.Stage,
+			JobID:        arg.JobID,
+			Notify:       arg.Notify,
+		})
+		return err
+	})
+
+	return asset, err
+}
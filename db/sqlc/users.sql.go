@@ -21,7 +21,7 @@ INSERT INTO "users" (
         provider
     )
 VALUES ($1, $2, $3, $4, $5)
-RETURNING uid, name, email, avatar, password, provider, "createdAt", "updatedAt", "passwordChangedAt"
+RETURNING uid, name, email, avatar, password, provider, "createdAt", "updatedAt", "passwordChangedAt", role
 `
 
 type CreateUserParams struct {
@@ -51,12 +51,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (Users,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.PasswordChangedAt,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT uid, name, email, avatar, password, provider, "createdAt", "updatedAt", "passwordChangedAt"
+SELECT uid, name, email, avatar, password, provider, "createdAt", "updatedAt", "passwordChangedAt", role
 FROM "users"
 WHERE email = $1
 LIMIT 1
@@ -75,12 +76,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (Users, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.PasswordChangedAt,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT uid, name, email, avatar, password, provider, "createdAt", "updatedAt", "passwordChangedAt"
+SELECT uid, name, email, avatar, password, provider, "createdAt", "updatedAt", "passwordChangedAt", role
 FROM "users"
 WHERE uid = $1
 LIMIT 1
@@ -99,6 +101,7 @@ func (q *Queries) GetUserById(ctx context.Context, uid uuid.UUID) (Users, error)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.PasswordChangedAt,
+		&i.Role,
 	)
 	return i, err
 }
@@ -110,7 +113,7 @@ SET email = $2,
     avatar = $4,
     "updatedAt" = now()
 WHERE uid = $1
-RETURNING uid, name, email, avatar, password, provider, "createdAt", "updatedAt", "passwordChangedAt"
+RETURNING uid, name, email, avatar, password, provider, "createdAt", "updatedAt", "passwordChangedAt", role
 `
 
 type UpdateUserParams struct {
@@ -138,6 +141,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (Users,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.PasswordChangedAt,
+		&i.Role,
 	)
 	return i, err
 }
@@ -147,7 +151,7 @@ UPDATE "users"
 SET password = $2,
     "passwordChangedAt" = now()
 WHERE uid = $1
-RETURNING uid, name, email, avatar, password, provider, "createdAt", "updatedAt", "passwordChangedAt"
+RETURNING uid, name, email, avatar, password, provider, "createdAt", "updatedAt", "passwordChangedAt", role
 `
 
 type UpdateUserPasswordParams struct {
@@ -168,6 +172,7 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.PasswordChangedAt,
+		&i.Role,
 	)
 	return i, err
 }
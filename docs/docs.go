@@ -15,6 +15,207 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/cleanup-jobs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the jobs that delete the files of removed assets, newest first, at most 100. By default only failed jobs are listed, whose orphaned paths could not be deleted from the storage after every retry or were left in place since they are outside of the storage of the asset owner and the output directory of the asset.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List cleanup jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, done or failed, defaults to failed",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cleanup jobs retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/api.listCleanupJobsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Admin role is required",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists messages that were rejected by a worker or the backend, newest first. Without an asset id only the latest 100 are returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List dead letters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Asset ID",
+                        "name": "assetId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dead letters retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/api.listDeadLettersResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Admin role is required",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dead-letters/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the dead-lettered message without publishing it again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Discard dead letter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dead letter discarded",
+                        "schema": {
+                            "$ref": "#/definitions/api.deadLetterResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Admin role is required",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Dead letter not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dead-letters/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Publishes the dead-lettered message to its original queue and removes it from the dead letters.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replay dead letter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dead letter replayed",
+                        "schema": {
+                            "$ref": "#/definitions/api.deadLetterResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Admin role is required",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Dead letter not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/workers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists registered workers, most recently seen first, marking those without a recent heartbeat as unhealthy. Also reports the queued and running jobs of each stage and estimates the queue wait from the throughput of the last hour.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List workers",
+                "responses": {
+                    "200": {
+                        "description": "Workers retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/api.listWorkersResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Admin role is required",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/assets": {
             "get": {
                 "description": "Retrieves a list of all assets, optionally filtered by keyword and tags, including their associated user details.",
@@ -97,12 +298,93 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.CreateAssetsResponse"
                         }
+                    },
+                    "400": {
+                        "description": "Error: Unknown asset type or missing point cloud",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Error: Photo directory or point cloud is not a valid file of the caller",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/assets/failure/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "WorkerAuth": []
+                    }
+                ],
+                "description": "Called by a GPU worker when COLMAP, Gaussian splatting, PTv3 or SAGA crashed. Moves the asset to the failed state and stores the reason.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "assets"
+                ],
+                "summary": "Report pipeline failure",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Asset ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Report Asset Failure Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ReportAssetFailureRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retrying the callback safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Failure recorded successfully",
+                        "schema": {
+                            "$ref": "#/definitions/api.ReportAssetFailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Illegal status transition",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Error: Asset was cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
         "/assets/gaussian/{id}": {
             "patch": {
+                "security": [
+                    {
+                        "WorkerAuth": []
+                    }
+                ],
                 "description": "Updates the URL for a specific gaussian asset based on the provided ID",
                 "consumes": [
                     "application/json"
@@ -130,6 +412,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.UpdateGaussianUrlRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retrying the callback safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -138,6 +426,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.UpdateGaussianUrlResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Error: Illegal status transition",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Error: Asset was cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             }
@@ -235,6 +535,11 @@ const docTemplate = `{
         },
         "/assets/pointcloud/{id}": {
             "patch": {
+                "security": [
+                    {
+                        "WorkerAuth": []
+                    }
+                ],
                 "description": "Updates the URL for a specific point cloud asset based on the provided ID",
                 "consumes": [
                     "application/json"
@@ -262,6 +567,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.UpdatePointCloudUrlRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retrying the callback safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -270,13 +581,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.UpdatePointCloudUrlResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Error: Illegal status transition",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Error: Asset was cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/assets/ptv3/{id}": {
+        "/assets/progress/{id}": {
             "patch": {
-                "description": "Updates the URL for a specific PTv3 asset based on the provided ID",
+                "security": [
+                    {
+                        "WorkerAuth": []
+                    }
+                ],
+                "description": "Called by a GPU worker while a stage runs, e.g. every few hundred splat training iterations. The latest report is stored on the asset until its status changes and is streamed to the event subscribers of the asset. Reports for a stage the asset already left are ignored.",
                 "consumes": [
                     "application/json"
                 ],
@@ -286,7 +614,7 @@ const docTemplate = `{
                 "tags": [
                     "assets"
                 ],
-                "summary": "Update PTv3 URL",
+                "summary": "Report stage progress",
                 "parameters": [
                     {
                         "type": "string",
@@ -296,27 +624,114 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Update PTv3 URL Request",
+                        "description": "Report Stage Progress Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.UpdatePTV3UrlRequest"
+                            "$ref": "#/definitions/api.ReportStageProgressRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "URL updated successfully",
+                        "description": "Progress recorded",
                         "schema": {
-                            "$ref": "#/definitions/api.UpdatePTV3UrlResponse"
+                            "$ref": "#/definitions/api.ReportStageProgressResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/assets/saga/segment/{id}": {
+                    },
+                    "404": {
+                        "description": "Error: Asset not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Job does not belong to the asset",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Error: Asset was cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/assets/ptv3/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "WorkerAuth": []
+                    }
+                ],
+                "description": "Updates the URL for a specific PTv3 asset based on the provided ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "assets"
+                ],
+                "summary": "Update PTv3 URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Asset ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update PTv3 URL Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdatePTV3UrlRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retrying the callback safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "URL updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdatePTV3UrlResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Illegal status transition",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Error: Asset was cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/assets/saga/segment/{id}": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Segment using SAGA by sending message to RabbitMQ",
                 "consumes": [
                     "application/json"
@@ -352,12 +767,23 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.SegmentUsingSagaResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Error: Asset not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             }
         },
         "/assets/saga/{id}": {
             "patch": {
+                "security": [
+                    {
+                        "WorkerAuth": []
+                    }
+                ],
                 "description": "Updates the URL for a specific saga asset based on the provided ID",
                 "consumes": [
                     "application/json"
@@ -385,6 +811,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.UpdateSagaUrlRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retrying the callback safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -393,6 +825,88 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.UpdateSagaUrlResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Error: Illegal status transition",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Error: Asset was cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/assets/start/{id}": {
+            "patch": {
+                "security": [
+                    {
+                        "WorkerAuth": []
+                    }
+                ],
+                "description": "Called by a GPU worker when it picks up a stage of an asset, so the wait and run time of the job can be told apart",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "assets"
+                ],
+                "summary": "Report stage start",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Asset ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Report Stage Start Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ReportStageStartRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retrying the callback safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Job started",
+                        "schema": {
+                            "$ref": "#/definitions/api.ReportStageStartResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Asset or job not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Asset is not running the stage",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Error: Asset was cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             }
@@ -441,7 +955,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Remove my asset",
+                "description": "Remove my asset. Its photos, point clouds, splats, segmentations and query outputs are deleted from the storage in the background, with retries; admins can list the files that could not be deleted at /admin/cleanup-jobs.",
                 "consumes": [
                     "application/json"
                 ],
@@ -471,116 +985,146 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/google": {
+        "/assets/{id}/cancel": {
             "post": {
-                "description": "Authenticate user with Google OAuth token",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Moves an asset that is still being processed to the cancelled state and tells the workers to stop. Results reported for the asset afterwards are rejected. A cancelled asset can be reprocessed later.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "assets"
                 ],
-                "summary": "Google Auth",
+                "summary": "Cancel asset processing",
                 "parameters": [
                     {
-                        "description": "Google OAuth token",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.googleRequest"
-                        }
+                        "type": "string",
+                        "description": "Asset ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Succes to login/sign up",
+                    "202": {
+                        "description": "Asset processing cancelled",
                         "schema": {
-                            "$ref": "#/definitions/api.googleResponse"
+                            "$ref": "#/definitions/api.CancelAssetResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Not the owner of the asset",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Asset is not being processed",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/signin": {
-            "post": {
-                "description": "Login user with the provided credentials",
-                "consumes": [
-                    "application/json"
+        "/assets/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Opens a Server-Sent Events stream of an asset. The first event is a snapshot of the asset, followed by status transitions, progress reported by the workers, stage outputs and SAGA segmentation completions. A client that falls behind receives a resync event and the stream ends, so it reconnects and starts over from a new snapshot. Private assets can only be streamed by their owner.",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "auth"
+                    "assets"
                 ],
-                "summary": "Login user",
+                "summary": "Stream asset events",
                 "parameters": [
                     {
-                        "description": "User login details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.loginUserRequest"
-                        }
+                        "type": "string",
+                        "description": "Asset ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User login successful",
+                        "description": "Stream of asset events",
                         "schema": {
-                            "$ref": "#/definitions/api.loginUserResponse"
+                            "$ref": "#/definitions/hub.Event"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Asset not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/signup": {
-            "post": {
-                "description": "Register a new user with the provided details",
-                "consumes": [
-                    "application/json"
+        "/assets/{id}/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Lists every pipeline stage run of an asset in the order they were enqueued, with attempts, workers and timing. Only the owner and admins can see them.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "assets"
                 ],
-                "summary": "Register a new user",
+                "summary": "Get asset jobs",
                 "parameters": [
                     {
-                        "description": "User registration details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.registerUserRequest"
-                        }
+                        "type": "string",
+                        "description": "Asset ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User registration successful",
+                        "description": "Jobs retrieved",
                         "schema": {
-                            "$ref": "#/definitions/api.registerUserResponse"
+                            "$ref": "#/definitions/api.getAssetJobsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Not the owner of the asset",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Asset not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/tags/search": {
-            "get": {
+        "/assets/{id}/reprocess": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves a list of tags that match the given search keyword",
+                "description": "Re-runs the processing pipeline of a finished, failed or cancelled asset starting from the given stage (the first stage of its pipeline by default). Outputs of that stage and every later stage are cleared.",
                 "consumes": [
                     "application/json"
                 ],
@@ -588,43 +1132,100 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "assets"
                 ],
-                "summary": "Get tags by search keyword",
+                "summary": "Reprocess asset",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search keyword",
-                        "name": "keyword",
-                        "in": "query",
+                        "description": "Asset ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Limit for number of tags returned",
-                        "name": "limit",
-                        "in": "query",
-                        "required": true
+                        "description": "Reprocess Asset Request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.ReprocessAssetRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Success",
+                    "202": {
+                        "description": "Asset reprocessing started",
                         "schema": {
-                            "$ref": "#/definitions/api.GetTagBySearchKeywordResponse"
+                            "$ref": "#/definitions/api.ReprocessAssetResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Not the owner of the asset",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Asset is already being processed",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/users": {
+        "/assets/{id}/segment/ws": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve user information",
+                "description": "Upgrades to a WebSocket on which the viewer sends click prompts ({\"type\":\"prompt\",\"x\":0,\"y\":0,\"url\":\"\",\"uniqueIdentifier\":\"\"}) and receives \"accepted\", \"result\" and \"error\" messages for each of them. A session that falls behind receives a \"resync\" message and is closed, so the viewer reconnects and sends its pending prompts again. Browsers may pass the access token as the access_token query parameter.",
+                "tags": [
+                    "assets"
+                ],
+                "summary": "SAGA segmentation session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Asset ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching protocols",
+                        "schema": {
+                            "$ref": "#/definitions/api.SegmentationReply"
+                        }
+                    },
+                    "401": {
+                        "description": "Error: Not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Asset not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/google": {
+            "post": {
+                "description": "Authenticate user with Google OAuth token",
                 "consumes": [
                     "application/json"
                 ],
@@ -632,25 +1233,67 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "auth"
+                ],
+                "summary": "Google Auth",
+                "parameters": [
+                    {
+                        "description": "Google OAuth token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.googleRequest"
+                        }
+                    }
                 ],
-                "summary": "Get user data",
                 "responses": {
                     "200": {
-                        "description": "User information retrieved successfully",
+                        "description": "Succes to login/sign up",
                         "schema": {
-                            "$ref": "#/definitions/api.UserResponse"
+                            "$ref": "#/definitions/api.googleResponse"
                         }
                     }
                 }
-            },
-            "patch": {
-                "security": [
+            }
+        },
+        "/auth/signin": {
+            "post": {
+                "description": "Login user with the provided credentials",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login user",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "description": "User login details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserRequest"
+                        }
                     }
                 ],
-                "description": "Update user information based on the provided user ID",
+                "responses": {
+                    "200": {
+                        "description": "User login successful",
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/signup": {
+            "post": {
+                "description": "Register a new user with the provided details",
                 "consumes": [
                     "application/json"
                 ],
@@ -658,38 +1301,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "auth"
                 ],
-                "summary": "Update user information",
+                "summary": "Register a new user",
                 "parameters": [
                     {
-                        "description": "User update details",
+                        "description": "User registration details",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.updateUserRequest"
+                            "$ref": "#/definitions/api.registerUserRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User information updated successfully",
+                        "description": "User registration successful",
                         "schema": {
-                            "$ref": "#/definitions/api.updateUserResponse"
+                            "$ref": "#/definitions/api.registerUserResponse"
                         }
                     }
                 }
             }
         },
-        "/users/password": {
-            "patch": {
+        "/tags/search": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Change user password based on the provided user ID",
+                "description": "Retrieves a list of tags that match the given search keyword",
                 "consumes": [
                     "application/json"
                 ],
@@ -697,198 +1340,1927 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "tags"
                 ],
-                "summary": "Change user password",
+                "summary": "Get tags by search keyword",
                 "parameters": [
                     {
-                        "description": "User password update details",
+                        "type": "string",
+                        "description": "Search keyword",
+                        "name": "keyword",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit for number of tags returned",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/api.GetTagBySearchKeywordResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a draft asset and starts a direct upload of its photos. The files are declared up front with their sizes and checked against the photo count, file type and total size limits. Photos may be jpg or png; a single ply file becomes the point cloud of the asset. Upload the files with PUT /uploads/{id}/files/{name}, POST /uploads/{id}/files or tus, then finalize the session to start processing the asset. Draft assets are only listed to their owner; removing one abandons its upload.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Create upload session",
+                "parameters": [
+                    {
+                        "description": "Create Upload Session Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.changeUserPasswordRequest"
+                            "$ref": "#/definitions/api.CreateUploadSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Upload session created",
+                        "schema": {
+                            "$ref": "#/definitions/api.uploadSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error: Invalid files or asset type",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Error: Too many photos or bytes",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Error: Unsupported file type",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "501": {
+                        "description": "Error: Storage server does not accept uploads",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Shows the files of an upload session and how many bytes of each were received, so an interrupted upload can resume at the right offset.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Get upload session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User password updated successfully",
+                        "description": "Upload session retrieved",
                         "schema": {
-                            "$ref": "#/definitions/api.changeUserPasswordResponse"
+                            "$ref": "#/definitions/api.uploadSessionResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Upload session not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.AssetResponse": {
-            "type": "object",
-            "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "isLikedByMe": {
-                    "type": "boolean"
-                },
-                "isPrivate": {
-                    "type": "boolean"
+        },
+        "/uploads/{id}/files": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams every file part of a multipart/form-data body into the declared file of the same name. Each file is written from the start, so this suits sets small enough to send in one request.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Upload files",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Files of the upload",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Files stored",
+                        "schema": {
+                            "$ref": "#/definitions/api.uploadFilesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Upload session or file not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: File was already uploaded or the session is finalized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Error: File is larger than declared",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Error: Unsupported file type",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/{id}/files/{name}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams the request body into a declared file of the upload session, starting at the given offset. The offset must equal the bytes received so far; the first chunk must start with the content the file extension promises.",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Upload file chunk",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset of the chunk in the file",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Chunk stored",
+                        "schema": {
+                            "$ref": "#/definitions/api.uploadFileResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Upload session or file not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Offset does not match or the session is finalized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Error: File is larger than declared",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Error: Unsupported file type",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/{id}/finalize": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Checks that every declared file was received in full and moves the draft asset of the session into the first stage of its pipeline, like POST /assets does for a new asset.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Finalize upload session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Processing of the uploaded asset started",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateAssetsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Upload session not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Files are incomplete, the session is finalized or its draft asset was removed",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/{id}/tus": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a tus upload for a file of the upload session, named by the filename key of the Upload-Metadata header. Declared files must be created with their declared size; other files are added to the session if it stays within the upload limits. Creating a file that exists again returns its upload, so a client that lost the url can resume it.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Create tus upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tus protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size of the file in bytes",
+                        "name": "Upload-Length",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tus metadata with the base64 encoded filename",
+                        "name": "Upload-Metadata",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Upload created, its url is in the Location header"
+                    },
+                    "400": {
+                        "description": "Error: Invalid length or metadata",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Upload session not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Size differs from the declared one or the session is finalized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Error: Unsupported tus version",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Error: Upload exceeds the limits",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Error: Unsupported file type",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "options": {
+                "description": "Lists the tus versions and extensions the upload endpoints support and the largest upload they accept.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Tus capabilities",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Tus capabilities in the response headers"
+                    }
+                }
+            }
+        },
+        "/uploads/{id}/tus/{name}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes the file and what was uploaded of it, and removes it from the upload session.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Terminate tus upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tus protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Upload terminated"
+                    },
+                    "404": {
+                        "description": "Error: Upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Session is finalized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Error: Unsupported tus version",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the bytes of the file received so far in the Upload-Offset header, where the next PATCH has to start.",
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Get tus upload offset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tus protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Offset in the Upload-Offset header"
+                    },
+                    "404": {
+                        "description": "Error: Upload not found"
+                    },
+                    "412": {
+                        "description": "Error: Unsupported tus version"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams the request body into the file from the offset in the Upload-Offset header, which must equal the bytes received so far. When the connection drops, what arrived is kept and the upload resumes from there.",
+                "consumes": [
+                    "application/offset+octet-stream"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Append to tus upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tus protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset of the data in the file",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Data stored, the new offset is in the Upload-Offset header"
+                    },
+                    "400": {
+                        "description": "Error: Invalid offset",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Offset does not match or the session is finalized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Error: Unsupported tus version",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Error: File is larger than declared",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Error: Wrong content type or file type",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve user information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user data",
+                "responses": {
+                    "200": {
+                        "description": "User information retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/api.UserResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update user information based on the provided user ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user information",
+                "parameters": [
+                    {
+                        "description": "User update details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.updateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User information updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/api.updateUserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/password": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change user password based on the provided user ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change user password",
+                "parameters": [
+                    {
+                        "description": "User password update details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.changeUserPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User password updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/api.changeUserPasswordResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the webhooks I registered",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get my webhooks",
+                "responses": {
+                    "200": {
+                        "description": "Webhooks retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/api.getWebhooksResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registers an endpoint that receives the selected events of my assets. Every request is signed with the returned secret: the X-Segment3d-Timestamp header holds the unix time it was sent at and the X-Segment3d-Signature header holds sha256=\u003chex HMAC-SHA256 of \"\u003ctimestamp\u003e.\u003cbody\u003e\"\u003e. Reject requests with an old timestamp to guard against replays. The url has to resolve to a public address.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "description": "Create Webhook Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Webhook created",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateWebhookResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Error: Url does not point at a public address",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a webhook together with its delivery log",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook deleted",
+                        "schema": {
+                            "$ref": "#/definitions/api.webhookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the url, the events or pauses a webhook. Omitted fields are left unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Webhook Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook updated",
+                        "schema": {
+                            "$ref": "#/definitions/api.webhookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Error: Url does not point at a public address",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the latest 50 deliveries of a webhook with their status, attempts and last error",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deliveries retrieved",
+                        "schema": {
+                            "$ref": "#/definitions/api.getWebhookDeliveriesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workers/heartbeat": {
+            "post": {
+                "security": [
+                    {
+                        "WorkerAuth": []
+                    }
+                ],
+                "description": "Called by a registered GPU worker periodically. Records the asset and stage it is working on, or that it is idle when both are omitted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workers"
+                ],
+                "summary": "Worker heartbeat",
+                "parameters": [
+                    {
+                        "description": "Heartbeat Worker Request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.HeartbeatWorkerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Heartbeat recorded",
+                        "schema": {
+                            "$ref": "#/definitions/api.workerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Worker is not registered",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workers/register": {
+            "post": {
+                "security": [
+                    {
+                        "WorkerAuth": []
+                    }
+                ],
+                "description": "Called by a GPU worker on startup. Records the stages it runs and its GPU model under the worker id of its token and clears its current job.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workers"
+                ],
+                "summary": "Register worker",
+                "parameters": [
+                    {
+                        "description": "Register Worker Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RegisterWorkerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Worker registered",
+                        "schema": {
+                            "$ref": "#/definitions/api.workerResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.AssetProgressResponse": {
+            "type": "object",
+            "properties": {
+                "etaSeconds": {
+                    "type": "integer"
+                },
+                "iteration": {
+                    "type": "integer"
+                },
+                "percent": {
+                    "type": "number"
+                },
+                "previewUrl": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.AssetResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "failureLogsUrl": {
+                    "type": "string"
+                },
+                "failureReason": {
+                    "type": "string"
+                },
+                "failureStage": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isLikedByMe": {
+                    "type": "boolean"
+                },
+                "isPrivate": {
+                    "type": "boolean"
+                },
+                "likes": {
+                    "type": "integer"
+                },
+                "pclColmapUrl": {
+                    "type": "string"
+                },
+                "pclUrl": {
+                    "type": "string"
+                },
+                "photoDirUrl": {
+                    "type": "string"
+                },
+                "progress": {
+                    "description": "Progress is the latest progress reported for the running stage.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.AssetProgressResponse"
+                        }
+                    ]
+                },
+                "segmentedPclDirUrl": {
+                    "type": "string"
+                },
+                "segmentedSplatDirUrl": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "splatUrl": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "thumbnailUrl": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/api.UserResponse"
+                }
+            }
+        },
+        "api.CancelAssetResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/api.AssetResponse"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CleanupJobResponse": {
+            "type": "object",
+            "properties": {
+                "assetId": {
+                    "type": "string"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "completedAt": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "orphaned": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "paths": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "uid": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateAssetRequest": {
+            "type": "object",
+            "required": [
+                "isPrivate",
+                "photoDirUrl",
+                "title",
+                "type"
+            ],
+            "properties": {
+                "isPrivate": {
+                    "type": "boolean"
+                },
+                "pclUrl": {
+                    "type": "string"
+                },
+                "photoDirUrl": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateAssetsResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/api.AssetResponse"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateUploadSessionRequest": {
+            "type": "object",
+            "required": [
+                "files",
+                "isPrivate",
+                "title",
+                "type"
+            ],
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api.UploadFileRequest"
+                    }
+                },
+                "isPrivate": {
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret is only returned once, when the webhook is created.",
+                    "type": "string"
+                },
+                "webhook": {
+                    "$ref": "#/definitions/api.WebhookResponse"
+                }
+            }
+        },
+        "api.DeadLetterResponse": {
+            "type": "object",
+            "properties": {
+                "assetId": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deathCount": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.GetTagBySearchKeywordResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.Tags"
+                    }
+                }
+            }
+        },
+        "api.HeartbeatWorkerRequest": {
+            "type": "object",
+            "properties": {
+                "assetId": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string",
+                    "enum": [
+                        "colmap",
+                        "splat",
+                        "ptv3",
+                        "saga"
+                    ]
+                }
+            }
+        },
+        "api.JobResponse": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "enqueuedAt": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "resultUrl": {
+                    "type": "string"
+                },
+                "runSeconds": {
+                    "description": "RunSeconds is the time the worker spent on the job.",
+                    "type": "number"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "waitSeconds": {
+                    "description": "WaitSeconds is the time spent queued before a worker picked the job up.",
+                    "type": "number"
+                },
+                "workerId": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.LikeAssetResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/api.AssetResponse"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.RegisterWorkerRequest": {
+            "type": "object",
+            "required": [
+                "stages"
+            ],
+            "properties": {
+                "gpuModel": {
+                    "type": "string"
+                },
+                "stages": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.ReportAssetFailureRequest": {
+            "type": "object",
+            "required": [
+                "error",
+                "stage"
+            ],
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "jobId": {
+                    "type": "string"
+                },
+                "logsUrl": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string",
+                    "enum": [
+                        "colmap",
+                        "splat",
+                        "ptv3",
+                        "saga"
+                    ]
+                }
+            }
+        },
+        "api.ReportAssetFailureResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/api.AssetResponse"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ReportStageProgressRequest": {
+            "type": "object",
+            "required": [
+                "stage"
+            ],
+            "properties": {
+                "etaSeconds": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "iteration": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "jobId": {
+                    "type": "string"
+                },
+                "percent": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "previewUrl": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string",
+                    "enum": [
+                        "colmap",
+                        "splat",
+                        "ptv3",
+                        "saga"
+                    ]
+                }
+            }
+        },
+        "api.ReportStageProgressResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "progress": {
+                    "$ref": "#/definitions/api.AssetProgressResponse"
+                }
+            }
+        },
+        "api.ReportStageStartRequest": {
+            "type": "object",
+            "required": [
+                "stage"
+            ],
+            "properties": {
+                "jobId": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string",
+                    "enum": [
+                        "colmap",
+                        "splat",
+                        "ptv3",
+                        "saga"
+                    ]
+                }
+            }
+        },
+        "api.ReportStageStartResponse": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/api.JobResponse"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ReprocessAssetRequest": {
+            "type": "object",
+            "properties": {
+                "fromStage": {
+                    "type": "string",
+                    "enum": [
+                        "colmap",
+                        "splat",
+                        "ptv3",
+                        "saga"
+                    ]
+                }
+            }
+        },
+        "api.ReprocessAssetResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/api.AssetResponse"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.SegmentUsingSagaRequest": {
+            "type": "object",
+            "required": [
+                "uniqueIdentifier",
+                "url",
+                "x",
+                "y"
+            ],
+            "properties": {
+                "uniqueIdentifier": {
+                    "type": "string"
                 },
-                "likes": {
+                "url": {
+                    "type": "string"
+                },
+                "x": {
                     "type": "integer"
                 },
-                "pclColmapUrl": {
+                "y": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.SegmentUsingSagaResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string"
                 },
-                "pclUrl": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.SegmentationReply": {
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "string"
                 },
-                "photoDirUrl": {
+                "type": {
                     "type": "string"
                 },
-                "segmentedPclDirUrl": {
+                "uniqueIdentifier": {
                     "type": "string"
                 },
-                "segmentedSplatDirUrl": {
+                "url": {
                     "type": "string"
+                }
+            }
+        },
+        "api.StageCapacityResponse": {
+            "type": "object",
+            "properties": {
+                "estimatedWaitSeconds": {
+                    "description": "EstimatedWaitSeconds is how long a job queued now waits at the current\nthroughput. It is null when nothing finished recently to base it on.",
+                    "type": "number"
                 },
-                "slug": {
+                "healthyWorkers": {
+                    "type": "integer"
+                },
+                "queued": {
+                    "type": "integer"
+                },
+                "running": {
+                    "type": "integer"
+                },
+                "stage": {
                     "type": "string"
                 },
-                "splatUrl": {
+                "succeededLastHour": {
+                    "description": "SucceededLastHour is the number of jobs of the stage that finished\nsuccessfully in the last hour.",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.UnlikeAssetResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/api.AssetResponse"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.UpdateGaussianUrlRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "jobId": {
                     "type": "string"
                 },
-                "status": {
+                "url": {
                     "type": "string"
+                }
+            }
+        },
+        "api.UpdateGaussianUrlResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/api.AssetResponse"
                 },
-                "thumbnailUrl": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.UpdatePTV3UrlRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "jobId": {
                     "type": "string"
                 },
-                "title": {
+                "url": {
                     "type": "string"
+                }
+            }
+        },
+        "api.UpdatePTV3UrlResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/api.AssetResponse"
                 },
-                "type": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.UpdatePointCloudUrlRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "jobId": {
                     "type": "string"
                 },
-                "updatedAt": {
+                "url": {
                     "type": "string"
+                }
+            }
+        },
+        "api.UpdatePointCloudUrlResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/api.AssetResponse"
                 },
-                "user": {
-                    "$ref": "#/definitions/api.UserResponse"
+                "message": {
+                    "type": "string"
                 }
             }
         },
-        "api.CreateAssetRequest": {
+        "api.UpdateSagaUrlRequest": {
             "type": "object",
             "required": [
-                "isPrivate",
-                "photoDirUrl",
-                "title",
-                "type"
+                "url"
             ],
             "properties": {
-                "isPrivate": {
-                    "type": "boolean"
-                },
-                "pclUrl": {
+                "jobId": {
                     "type": "string"
                 },
-                "photoDirUrl": {
+                "url": {
                     "type": "string"
+                }
+            }
+        },
+        "api.UpdateSagaUrlResponse": {
+            "type": "object",
+            "properties": {
+                "asset": {
+                    "$ref": "#/definitions/api.AssetResponse"
                 },
-                "tags": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.UpdateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "events": {
                     "type": "array",
+                    "minItems": 1,
                     "items": {
                         "type": "string"
                     }
                 },
-                "title": {
-                    "type": "string"
+                "isActive": {
+                    "type": "boolean"
                 },
-                "type": {
-                    "type": "string",
-                    "enum": [
-                        "lidar",
-                        "non_lidar"
-                    ]
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "api.CreateAssetsResponse": {
+        "api.UploadFileRequest": {
             "type": "object",
+            "required": [
+                "name",
+                "size"
+            ],
             "properties": {
-                "asset": {
-                    "$ref": "#/definitions/api.AssetResponse"
-                },
-                "message": {
+                "name": {
                     "type": "string"
+                },
+                "size": {
+                    "type": "integer",
+                    "minimum": 1
                 }
             }
         },
-        "api.ErrorResponse": {
+        "api.UploadFileResponse": {
             "type": "object",
             "properties": {
-                "error": {
+                "complete": {
+                    "type": "boolean"
+                },
+                "name": {
                     "type": "string"
+                },
+                "received": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
                 }
             }
         },
-        "api.GetTagBySearchKeywordResponse": {
+        "api.UploadSessionResponse": {
             "type": "object",
             "properties": {
-                "message": {
+                "assetId": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.UploadFileResponse"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isPrivate": {
+                    "type": "boolean"
+                },
+                "status": {
                     "type": "string"
                 },
                 "tags": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/db.Tags"
+                        "type": "string"
                     }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "api.LikeAssetResponse": {
+        "api.UserResponse": {
             "type": "object",
             "properties": {
-                "asset": {
-                    "$ref": "#/definitions/api.AssetResponse"
+                "avatar": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "passwordChangedAt": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
                 },
-                "message": {
+                "id": {
                     "type": "string"
-                }
-            }
-        },
-        "api.SegmentUsingSagaRequest": {
-            "type": "object",
-            "required": [
-                "uniqueIdentifier",
-                "url",
-                "x",
-                "y"
-            ],
-            "properties": {
-                "uniqueIdentifier": {
+                },
+                "lastError": {
                     "type": "string"
                 },
-                "url": {
+                "nextAttemptAt": {
                     "type": "string"
                 },
-                "x": {
-                    "type": "integer"
+                "payload": {
+                    "type": "object"
                 },
-                "y": {
+                "responseStatus": {
                     "type": "integer"
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "api.SegmentUsingSagaResponse": {
+        "api.WebhookResponse": {
             "type": "object",
             "properties": {
-                "message": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isActive": {
+                    "type": "boolean"
+                },
+                "updatedAt": {
                     "type": "string"
                 },
                 "url": {
@@ -896,148 +3268,158 @@ const docTemplate = `{
                 }
             }
         },
-        "api.UnlikeAssetResponse": {
+        "api.WorkerResponse": {
             "type": "object",
             "properties": {
-                "asset": {
-                    "$ref": "#/definitions/api.AssetResponse"
+                "currentAssetId": {
+                    "type": "string"
                 },
-                "message": {
+                "currentStage": {
                     "type": "string"
-                }
-            }
-        },
-        "api.UpdateGaussianUrlRequest": {
-            "type": "object",
-            "required": [
-                "url"
-            ],
-            "properties": {
-                "url": {
+                },
+                "gpuModel": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastSeenAt": {
+                    "type": "string"
+                },
+                "registeredAt": {
                     "type": "string"
+                },
+                "stages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "api.UpdateGaussianUrlResponse": {
+        "api.changeUserPasswordRequest": {
             "type": "object",
             "properties": {
-                "asset": {
-                    "$ref": "#/definitions/api.AssetResponse"
+                "newPassword": {
+                    "type": "string"
                 },
-                "message": {
+                "oldPassword": {
                     "type": "string"
                 }
             }
         },
-        "api.UpdatePTV3UrlRequest": {
+        "api.changeUserPasswordResponse": {
             "type": "object",
-            "required": [
-                "url"
-            ],
             "properties": {
-                "url": {
+                "message": {
                     "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/api.UserResponse"
                 }
             }
         },
-        "api.UpdatePTV3UrlResponse": {
+        "api.deadLetterResponse": {
             "type": "object",
             "properties": {
-                "asset": {
-                    "$ref": "#/definitions/api.AssetResponse"
+                "deadLetter": {
+                    "$ref": "#/definitions/api.DeadLetterResponse"
                 },
                 "message": {
                     "type": "string"
                 }
             }
         },
-        "api.UpdatePointCloudUrlRequest": {
+        "api.getAllAssetsResponse": {
             "type": "object",
-            "required": [
-                "url"
-            ],
             "properties": {
-                "url": {
+                "assets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AssetResponse"
+                    }
+                },
+                "message": {
                     "type": "string"
                 }
             }
         },
-        "api.UpdatePointCloudUrlResponse": {
+        "api.getAssetJobsResponse": {
             "type": "object",
             "properties": {
-                "asset": {
-                    "$ref": "#/definitions/api.AssetResponse"
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.JobResponse"
+                    }
                 },
                 "message": {
                     "type": "string"
                 }
             }
         },
-        "api.UpdateSagaUrlRequest": {
+        "api.getMyAssetsResponse": {
             "type": "object",
-            "required": [
-                "url"
-            ],
             "properties": {
-                "url": {
+                "assets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AssetResponse"
+                    }
+                },
+                "message": {
                     "type": "string"
                 }
             }
         },
-        "api.UpdateSagaUrlResponse": {
+        "api.getWebhookDeliveriesResponse": {
             "type": "object",
             "properties": {
-                "asset": {
-                    "$ref": "#/definitions/api.AssetResponse"
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WebhookDeliveryResponse"
+                    }
                 },
                 "message": {
                     "type": "string"
                 }
             }
         },
-        "api.UserResponse": {
+        "api.getWebhooksResponse": {
             "type": "object",
             "properties": {
-                "avatar": {
-                    "type": "string"
-                },
-                "createdAt": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "passwordChangedAt": {
-                    "type": "string"
-                },
-                "provider": {
+                "message": {
                     "type": "string"
                 },
-                "updatedAt": {
-                    "type": "string"
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WebhookResponse"
+                    }
                 }
             }
         },
-        "api.changeUserPasswordRequest": {
+        "api.googleRequest": {
             "type": "object",
+            "required": [
+                "token"
+            ],
             "properties": {
-                "newPassword": {
-                    "type": "string"
-                },
-                "oldPassword": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "api.changeUserPasswordResponse": {
+        "api.googleResponse": {
             "type": "object",
             "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
                 "message": {
                     "type": "string"
                 },
@@ -1046,13 +3428,13 @@ const docTemplate = `{
                 }
             }
         },
-        "api.getAllAssetsResponse": {
+        "api.listCleanupJobsResponse": {
             "type": "object",
             "properties": {
-                "assets": {
+                "cleanupJobs": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/api.AssetResponse"
+                        "$ref": "#/definitions/api.CleanupJobResponse"
                     }
                 },
                 "message": {
@@ -1060,13 +3442,13 @@ const docTemplate = `{
                 }
             }
         },
-        "api.getMyAssetsResponse": {
+        "api.listDeadLettersResponse": {
             "type": "object",
             "properties": {
-                "assets": {
+                "deadLetters": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/api.AssetResponse"
+                        "$ref": "#/definitions/api.DeadLetterResponse"
                     }
                 },
                 "message": {
@@ -1074,28 +3456,23 @@ const docTemplate = `{
                 }
             }
         },
-        "api.googleRequest": {
-            "type": "object",
-            "required": [
-                "token"
-            ],
-            "properties": {
-                "token": {
-                    "type": "string"
-                }
-            }
-        },
-        "api.googleResponse": {
+        "api.listWorkersResponse": {
             "type": "object",
             "properties": {
-                "accessToken": {
-                    "type": "string"
-                },
                 "message": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/api.UserResponse"
+                "stages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.StageCapacityResponse"
+                    }
+                },
+                "workers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WorkerResponse"
+                    }
                 }
             }
         },
@@ -1196,6 +3573,64 @@ const docTemplate = `{
                 }
             }
         },
+        "api.uploadFileResponse": {
+            "type": "object",
+            "properties": {
+                "file": {
+                    "$ref": "#/definitions/api.UploadFileResponse"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.uploadFilesResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.UploadFileResponse"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.uploadSessionResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "session": {
+                    "$ref": "#/definitions/api.UploadSessionResponse"
+                }
+            }
+        },
+        "api.webhookResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "webhook": {
+                    "$ref": "#/definitions/api.WebhookResponse"
+                }
+            }
+        },
+        "api.workerResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "worker": {
+                    "$ref": "#/definitions/api.WorkerResponse"
+                }
+            }
+        },
         "db.Tags": {
             "type": "object",
             "properties": {
@@ -1215,6 +3650,18 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "hub.Event": {
+            "type": "object",
+            "properties": {
+                "assetId": {
+                    "type": "string"
+                },
+                "data": {},
+                "type": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -1222,6 +3669,11 @@ const docTemplate = `{
             "type": "apiKey",
             "name": "Authorization",
             "in": "header"
+        },
+        "WorkerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
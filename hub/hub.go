@@ -0,0 +1,84 @@
+package hub
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// bufferSize is the number of events a subscriber may fall behind before it
+// is unsubscribed.
+const bufferSize = 16
+
+// Event is something that happened to an asset.
+type Event struct {
+	Type    string    `json:"type"`
+	AssetID uuid.UUID `json:"assetId"`
+	Data    any       `json:"data"`
+}
+
+// Hub fans events of an asset out to every subscriber of that asset within
+// this process.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[chan Event]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[uuid.UUID]map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving the events of the asset and a function
+// that ends the subscription and closes the channel. The channel is also
+// closed when the subscriber falls too far behind, see Publish.
+func (hub *Hub) Subscribe(assetID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, bufferSize)
+
+	hub.mu.Lock()
+	if hub.subscribers[assetID] == nil {
+		hub.subscribers[assetID] = make(map[chan Event]struct{})
+	}
+	hub.subscribers[assetID][ch] = struct{}{}
+	hub.mu.Unlock()
+
+	return ch, func() { hub.remove(assetID, ch) }
+}
+
+// Publish sends the event to the subscribers of its asset without blocking.
+// Rather than missing the event silently, a subscriber that is not keeping up
+// is unsubscribed: its channel is closed once it received the events buffered
+// so far, which tells it to resync.
+func (hub *Hub) Publish(event Event) {
+	var lagging []chan Event
+
+	hub.mu.RLock()
+	for ch := range hub.subscribers[event.AssetID] {
+		select {
+		case ch <- event:
+		default:
+			lagging = append(lagging, ch)
+		}
+	}
+	hub.mu.RUnlock()
+
+	for _, ch := range lagging {
+		hub.remove(event.AssetID, ch)
+	}
+}
+
+// remove ends the subscription of the channel and closes it, unless that
+// happened already.
+func (hub *Hub) remove(assetID uuid.UUID, ch chan Event) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if _, ok := hub.subscribers[assetID][ch]; !ok {
+		return
+	}
+
+	delete(hub.subscribers[assetID], ch)
+	if len(hub.subscribers[assetID]) == 0 {
+		delete(hub.subscribers, assetID)
+	}
+	close(ch)
+}
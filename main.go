@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 
 	_ "github.com/lib/pq"
 	"github.com/segment3d-app/segment3d-be/api"
+	"github.com/segment3d-app/segment3d-be/cleanup"
 	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/outbox"
+	"github.com/segment3d-app/segment3d-be/pipeline"
 	"github.com/segment3d-app/segment3d-be/rabbitmq"
+	"github.com/segment3d-app/segment3d-be/storage"
 	"github.com/segment3d-app/segment3d-be/util"
+	"github.com/segment3d-app/segment3d-be/webhook"
 	_ "github.com/swaggo/files"
 	_ "github.com/swaggo/gin-swagger"
 )
@@ -27,6 +34,10 @@ type Message struct {
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apikey WorkerAuth
+// @in header
+// @name Authorization
 func main() {
 	// configuration
 	config, err := util.LoadConfig(".")
@@ -41,21 +52,97 @@ func main() {
 	}
 	store := db.NewStore(conn)
 
+	// pipelines run by each asset type
+	pipelines, err := pipeline.LoadDefinitions(config.PipelinesFile)
+	if err != nil {
+		log.Fatal("can't load pipeline definitions: ", err)
+	}
+
 	// rabbitmq
-	rabbitmq, err := rabbitmq.NewRabbitMq(config.RabbitSource)
+	rabbitmq, err := rabbitmq.NewRabbitMq(config.RabbitSource, api.PublishedQueues(pipelines)...)
 	if err != nil {
 		log.Fatal("can't connect to rabbitmq: ", err)
 	}
+	if len(config.RabbitManagementUrl) > 0 {
+		queues := append(api.PublishedQueues(pipelines), api.ConsumedQueues()...)
+		problems, err := rabbitmq.CheckQueues(context.Background(), config.RabbitManagementUrl, queues)
+		if err != nil {
+			log.Fatal("can't check rabbitmq queues: ", err)
+		}
+		for _, problem := range problems {
+			log.Printf("WARNING: %s, see README.md", problem)
+		}
+	}
+
+	// storage the uploads and pipeline outputs are kept in
+	storage, err := newStorage(config)
+	if err != nil {
+		log.Fatal("can't set up storage: ", err)
+	}
 
 	// server
-	server, err := api.NewServer(&config, store, rabbitmq)
+	server, err := api.NewServer(&config, store, rabbitmq, pipelines, storage)
 	if err != nil {
 		log.Fatal("can't create server: ", err)
 	}
 
+	// consume results published by the workers
+	err = server.ConsumePipelineResults()
+	if err != nil {
+		log.Fatal("can't consume pipeline results: ", err)
+	}
+
+	// push segmentation results to the viewer sessions
+	err = server.ConsumeQueryResults()
+	if err != nil {
+		log.Fatal("can't consume query results: ", err)
+	}
+
+	// keep messages rejected by the workers for inspection
+	err = server.ConsumeDeadLetters()
+	if err != nil {
+		log.Fatal("can't consume dead letters: ", err)
+	}
+
+	// publish events queued in the outbox
+	relay := outbox.NewRelay(store, rabbitmq, outbox.Schedule{
+		MaxJobsPerUser: config.MaxJobsPerUser,
+		Priority:       config.ProcessPriority,
+	})
+	go relay.Run(context.Background())
+
+	// deliver webhooks registered by the users
+	dispatcher := webhook.NewDispatcher(store)
+	go dispatcher.Run(context.Background())
+
+	// delete the files of removed assets
+	cleaner := cleanup.NewCleaner(store, storage)
+	go cleaner.Run(context.Background())
+
+	// recover assets stuck in a stage
+	go server.RunWatchdog(context.Background())
+
 	// start server
 	err = server.Start(config.ServerAddress)
 	if err != nil {
 		log.Fatal("can't start server: ", err)
 	}
 }
+
+// newStorage returns the storage server client, or a local directory when
+// STORAGE_BACKEND is "local". STORAGE_SERVER_CAPABILITIES lists the optional
+// endpoints the storage server offers.
+func newStorage(config util.Config) (storage.Storage, error) {
+	switch config.StorageBackend {
+	case "", "http":
+		capabilities, err := storage.ParseCapabilities(config.StorageCapabilities)
+		if err != nil {
+			return nil, err
+		}
+		return storage.NewHTTPStorage(config.StorageUrl, config.StorageSigningKey, capabilities), nil
+	case "local":
+		return storage.NewLocalStorage(config.StorageLocalDir, config.StorageUrl, config.StorageSigningKey)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", config.StorageBackend)
+	}
+}
@@ -0,0 +1,180 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+	"github.com/segment3d-app/segment3d-be/rabbitmq"
+)
+
+const (
+	// pollInterval is how often the relay looks for pending events.
+	pollInterval = 2 * time.Second
+	// batchSize is the maximum number of events claimed per poll.
+	batchSize = 50
+	// leaseDuration keeps a claimed event from being claimed again while it is
+	// being published. An event whose relay died mid-publish is retried once
+	// the lease runs out.
+	leaseDuration = time.Minute
+	// maxBackoff caps the delay between retries of a failing event.
+	maxBackoff = 5 * time.Minute
+	// deferDelay is how long an event of a user at the in-flight limit waits
+	// before it is considered again.
+	deferDelay = 10 * time.Second
+	// defaultPriority is the priority of pipeline events when none is set.
+	defaultPriority = 5
+)
+
+// Schedule controls how the relay releases the pipeline events of the users,
+// so that one user's bulk upload cannot hold up everyone else.
+type Schedule struct {
+	// MaxJobsPerUser caps the stage jobs of one user that are published but
+	// not finished. Further events of the user stay in the outbox until one
+	// finishes. Zero disables the limit.
+	MaxJobsPerUser int
+	// Priority is the priority of a pipeline event of a user with no jobs in
+	// flight. It drops by one for every job the user already has in flight,
+	// so the messages of light users overtake those of heavy ones.
+	Priority int
+}
+
+// Relay publishes the events queued in the outbox table to RabbitMQ. Events
+// are marked sent only after the broker accepted them, so delivery is at
+// least once and consumers have to tolerate duplicates.
+type Relay struct {
+	store     db.Store
+	publisher rabbitmq.Publisher
+	schedule  Schedule
+	priority  uint8
+}
+
+func NewRelay(store db.Store, publisher rabbitmq.Publisher, schedule Schedule) *Relay {
+	priority := uint8(defaultPriority)
+	if schedule.Priority > 0 {
+		priority = rabbitmq.Priority(schedule.Priority)
+	}
+
+	return &Relay{store: store, publisher: publisher, schedule: schedule, priority: priority}
+}
+
+// Run publishes pending events until the context is cancelled.
+func (relay *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := relay.publishPending(ctx)
+			if err != nil {
+				log.Printf("can't relay outbox events: %v", err)
+			}
+			// keep draining while full batches come back
+			if err != nil || n < batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishPending claims a batch of due events and publishes them, returning
+// how many were claimed.
+func (relay *Relay) publishPending(ctx context.Context) (int, error) {
+	events, err := relay.store.ClaimOutboxEvents(ctx, db.ClaimOutboxEventsParams{
+		Limit:         batchSize,
+		NextAttemptAt: time.Now().Add(leaseDuration),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		priority, ok, err := relay.admit(ctx, event)
+		if err != nil {
+			return len(events), err
+		}
+		if !ok {
+			continue
+		}
+
+		err = relay.publish(event, priority)
+		if err != nil {
+			log.Printf("can't publish outbox event %s to %s%s (attempt %d): %v", event.ID, event.Exchange, event.Queue, event.Attempts, err)
+			err = relay.store.MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{
+				ID:            event.ID,
+				LastError:     sql.NullString{String: err.Error(), Valid: true},
+				NextAttemptAt: time.Now().Add(backoff(event.Attempts)),
+			})
+			if err != nil {
+				return len(events), err
+			}
+			continue
+		}
+
+		err = relay.store.MarkOutboxEventSent(ctx, event.ID)
+		if err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
+
+// admit decides whether the event may be published now and with which
+// priority. Pipeline events carry the uid of the asset owner; those of users
+// at the in-flight limit are deferred instead.
+func (relay *Relay) admit(ctx context.Context, event db.Outbox) (uint8, bool, error) {
+	if !event.Uid.Valid {
+		return 0, true, nil
+	}
+
+	inFlight, err := relay.store.CountInFlightJobsByUser(ctx, event.Uid.UUID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if relay.schedule.MaxJobsPerUser > 0 && inFlight >= int64(relay.schedule.MaxJobsPerUser) {
+		err = relay.store.DeferOutboxEvent(ctx, db.DeferOutboxEventParams{
+			ID:            event.ID,
+			NextAttemptAt: time.Now().Add(deferDelay),
+		})
+		return 0, false, err
+	}
+
+	if inFlight >= int64(relay.priority) {
+		return 0, true, nil
+	}
+
+	return relay.priority - uint8(inFlight), true, nil
+}
+
+// publish broadcasts events that name an exchange and sends the others to
+// their queue.
+func (relay *Relay) publish(event db.Outbox, priority uint8) error {
+	if event.Exchange != "" {
+		return relay.publisher.Broadcast(event.Exchange, event.Payload)
+	}
+
+	return relay.publisher.PublishEvent(event.Queue, event.Payload, priority)
+}
+
+// backoff doubles the retry delay with every attempt, starting at one second.
+func backoff(attempts int32) time.Duration {
+	delay := time.Second
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,270 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Step is a stage of a pipeline along with the queue it is published to and
+// the column its result is stored in.
+type Step struct {
+	Stage  Stage  `json:"stage"`
+	Queue  string `json:"queue"`
+	Output Output `json:"output"`
+}
+
+// Definition lists the steps an asset runs through, in order.
+type Definition struct {
+	Steps []Step
+}
+
+// Default runs every stage on its usual queue.
+var Default = mustDefinition(stepsOf(Stages...))
+
+// Definitions maps asset types to the pipeline assets of that type run.
+type Definitions map[string]Definition
+
+// builtinTypes are the asset types the app supports without configuration.
+var builtinTypes = []string{"lidar", "non_lidar"}
+
+// NewDefinition checks the steps and fills in the default queue and output of
+// steps that leave them empty. Every output is written by one stage at most,
+// and stages run after those whose outputs they read. A pipeline that starts
+// at splat works from the point cloud uploaded with the asset.
+func NewDefinition(steps []Step) (Definition, error) {
+	if len(steps) == 0 {
+		return Definition{}, fmt.Errorf("pipeline has no stages")
+	}
+
+	// later stages need a splat, which only colmap and splat can start from
+	if first := steps[0].Stage; first != StageColmap && first != StageSplat {
+		return Definition{}, fmt.Errorf("pipeline must start at %s or %s, not %s", StageColmap, StageSplat, first)
+	}
+
+	seen := make(map[Stage]bool)
+	writers := make(map[Output]int)
+	for i := range steps {
+		step := &steps[i]
+		if !step.Stage.IsValid() {
+			return Definition{}, fmt.Errorf("unknown pipeline stage %q", step.Stage)
+		}
+		if seen[step.Stage] {
+			return Definition{}, fmt.Errorf("stage %s is listed twice", step.Stage)
+		}
+		seen[step.Stage] = true
+
+		if len(step.Queue) == 0 {
+			step.Queue = step.Stage.Queue()
+		}
+		if len(step.Output) == 0 {
+			step.Output = step.Stage.Output()
+		}
+		if !step.Output.IsValid() {
+			return Definition{}, fmt.Errorf("stage %s has unknown output %q", step.Stage, step.Output)
+		}
+		if j, ok := writers[step.Output]; ok {
+			return Definition{}, fmt.Errorf("stages %s and %s both write %s", steps[j].Stage, step.Stage, step.Output)
+		}
+		writers[step.Output] = i
+	}
+
+	for i, step := range steps {
+		for _, input := range stageInputs[step.Stage] {
+			j, ok := writers[input.output]
+			if !ok && input.required {
+				return Definition{}, fmt.Errorf("stage %s needs %s, which no stage of the pipeline produces", step.Stage, input.output)
+			}
+			if ok && j > i {
+				return Definition{}, fmt.Errorf("stage %s needs %s, so it has to run after %s", step.Stage, input.output, steps[j].Stage)
+			}
+		}
+	}
+
+	return Definition{Steps: steps}, nil
+}
+
+func mustDefinition(steps []Step) Definition {
+	def, err := NewDefinition(steps)
+	if err != nil {
+		panic(err)
+	}
+
+	return def
+}
+
+func stepsOf(stages ...Stage) []Step {
+	steps := make([]Step, len(stages))
+	for i, stage := range stages {
+		steps[i] = Step{Stage: stage}
+	}
+
+	return steps
+}
+
+// LoadDefinitions reads the pipelines of the asset types from a JSON file that
+// maps each type to its steps, e.g.
+//
+//	{"splat_only": [{"stage": "colmap"}, {"stage": "splat"}]}
+//
+// The builtin types run the default pipeline unless the file overrides them.
+// Without a file only the builtin types are known.
+func LoadDefinitions(path string) (Definitions, error) {
+	defs := make(Definitions)
+	for _, assetType := range builtinTypes {
+		defs[assetType] = Default
+	}
+
+	if len(path) == 0 {
+		return defs, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string][]Step
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode pipeline definitions: %w", err)
+	}
+
+	for assetType, steps := range raw {
+		def, err := NewDefinition(steps)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline of type %s: %w", assetType, err)
+		}
+		defs[assetType] = def
+	}
+
+	return defs, nil
+}
+
+// Has reports whether assets of the type may be created.
+func (defs Definitions) Has(assetType string) bool {
+	_, ok := defs[assetType]
+	return ok
+}
+
+// For returns the pipeline of the asset type. Assets whose type is no longer
+// configured run the default pipeline.
+func (defs Definitions) For(assetType string) Definition {
+	def, ok := defs[assetType]
+	if !ok {
+		return Default
+	}
+
+	return def
+}
+
+// Queues returns every queue a stage of the pipelines is published to.
+func (defs Definitions) Queues() []string {
+	types := make([]string, 0, len(defs))
+	for assetType := range defs {
+		types = append(types, assetType)
+	}
+	sort.Strings(types)
+
+	seen := make(map[string]bool)
+	queues := []string{}
+	for _, assetType := range types {
+		for _, step := range defs[assetType].Steps {
+			if !seen[step.Queue] {
+				seen[step.Queue] = true
+				queues = append(queues, step.Queue)
+			}
+		}
+	}
+
+	return queues
+}
+
+// Step returns the step of the stage if the pipeline runs it.
+func (def Definition) Step(stage Stage) (Step, bool) {
+	for _, step := range def.Steps {
+		if step.Stage == stage {
+			return step, true
+		}
+	}
+
+	return Step{}, false
+}
+
+// First returns the step a new asset starts at.
+func (def Definition) First() Step {
+	return def.Steps[0]
+}
+
+// Next returns the status an asset moves to once the stage has finished.
+func (def Definition) Next(stage Stage) State {
+	for i, step := range def.Steps {
+		if step.Stage == stage && i+1 < len(def.Steps) {
+			return def.Steps[i+1].Stage.State()
+		}
+	}
+
+	return StateCompleted
+}
+
+// OutputsFrom returns the columns written by the stage and every later one,
+// which a reprocess from the stage clears.
+func (def Definition) OutputsFrom(stage Stage) []string {
+	outputs := []string{}
+	found := false
+	for _, step := range def.Steps {
+		found = found || step.Stage == stage
+		if found {
+			outputs = append(outputs, string(step.Output))
+		}
+	}
+
+	return outputs
+}
+
+// Transition checks whether an asset running this pipeline may move from
+// state from to state to. Running assets advance through the stages in order
+// and may fail or be cancelled at any point. Drafts may only start at the
+// first stage once their upload is finalized. Finished assets may only be
+// reprocessed, which moves them straight into any stage of the pipeline.
+func (def Definition) Transition(from State, to State) error {
+	if !from.IsValid() {
+		return fmt.Errorf("%w: unknown status %q", ErrIllegalTransition, from)
+	}
+
+	if from == StateCancelled && !def.runs(to) {
+		return ErrCancelled
+	}
+
+	if from == StateDraft {
+		if to == def.First().Stage.State() {
+			return nil
+		}
+	} else if from.IsTerminal() {
+		if def.runs(to) {
+			return nil
+		}
+	} else if to == StateFailed || to == StateCancelled {
+		return nil
+	} else if from == StateCreated {
+		if def.runs(to) {
+			return nil
+		}
+	} else if stage, ok := StageOf(from); ok && to == def.Next(stage) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q to %q", ErrIllegalTransition, from, to)
+}
+
+// runs reports whether the state is a stage of the pipeline.
+func (def Definition) runs(state State) bool {
+	stage, ok := StageOf(state)
+	if !ok {
+		return false
+	}
+
+	_, ok = def.Step(stage)
+	return ok
+}
@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTransition(t *testing.T) {
+	splatOnly := mustDefinition(stepsOf(StageSplat, StagePTv3))
+
+	tests := []struct {
+		name string
+		def  Definition
+		from State
+		to   State
+		want error
+	}{
+		{"draft starts at the first stage", Default, StateDraft, StateGeneratingPointCloud, nil},
+		{"draft skips the first stage", Default, StateDraft, StateGeneratingSplat, ErrIllegalTransition},
+		{"draft fails", Default, StateDraft, StateFailed, ErrIllegalTransition},
+		{"draft starts a pipeline at splat", splatOnly, StateDraft, StateGeneratingSplat, nil},
+		{"created enters a stage", Default, StateCreated, StateGeneratingSplat, nil},
+		{"created completes", Default, StateCreated, StateCompleted, ErrIllegalTransition},
+		{"created enters a stage the pipeline skips", splatOnly, StateCreated, StateGeneratingPointCloud, ErrIllegalTransition},
+		{"stage advances", Default, StateGeneratingPointCloud, StateGeneratingSplat, nil},
+		{"last stage completes", Default, StateProcessingSaga, StateCompleted, nil},
+		{"last stage of a shorter pipeline completes", splatOnly, StateProcessingPTv3, StateCompleted, nil},
+		{"stage skips the next one", Default, StateGeneratingPointCloud, StateProcessingPTv3, ErrIllegalTransition},
+		{"stage goes back", Default, StateGeneratingSplat, StateGeneratingPointCloud, ErrIllegalTransition},
+		{"stage completes early", Default, StateGeneratingPointCloud, StateCompleted, ErrIllegalTransition},
+		{"stage fails", Default, StateGeneratingSplat, StateFailed, nil},
+		{"stage is cancelled", Default, StateProcessingPTv3, StateCancelled, nil},
+		{"completed is reprocessed", Default, StateCompleted, StateGeneratingPointCloud, nil},
+		{"completed is reprocessed from a later stage", Default, StateCompleted, StateProcessingSaga, nil},
+		{"completed fails", Default, StateCompleted, StateFailed, ErrIllegalTransition},
+		{"failed is reprocessed", Default, StateFailed, StateGeneratingSplat, nil},
+		{"failed is reprocessed at a stage the pipeline skips", splatOnly, StateFailed, StateProcessingSaga, ErrIllegalTransition},
+		{"failed completes", Default, StateFailed, StateCompleted, ErrIllegalTransition},
+		{"cancelled is reprocessed", Default, StateCancelled, StateGeneratingPointCloud, nil},
+		{"cancelled advances", Default, StateCancelled, StateCompleted, ErrCancelled},
+		{"cancelled fails", Default, StateCancelled, StateFailed, ErrCancelled},
+		{"unknown status", Default, State("unknown"), StateFailed, ErrIllegalTransition},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.def.Transition(test.from, test.to)
+			if test.want == nil && err != nil {
+				t.Fatalf("%q to %q: got error %v, want none", test.from, test.to, err)
+			}
+			if test.want != nil && !errors.Is(err, test.want) {
+				t.Fatalf("%q to %q: got error %v, want %v", test.from, test.to, err, test.want)
+			}
+		})
+	}
+}
+
+func TestNewDefinition(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []Step
+		want  string
+	}{
+		{"every stage", stepsOf(Stages...), ""},
+		{"starts at splat", stepsOf(StageSplat, StagePTv3, StageSaga), ""},
+		{"saga without ptv3", stepsOf(StageColmap, StageSplat, StageSaga), ""},
+		{"no stages", nil, "has no stages"},
+		{"starts at ptv3", stepsOf(StagePTv3, StageSplat), "must start at"},
+		{"unknown stage", stepsOf(StageColmap, Stage("mesh")), "unknown pipeline stage"},
+		{"stage listed twice", stepsOf(StageColmap, StageSplat, StageSplat), "listed twice"},
+		{"unknown output", []Step{{Stage: StageColmap, Output: Output("meshUrl")}}, "unknown output"},
+		{"output written twice", []Step{{Stage: StageColmap, Output: OutputSplatUrl}, {Stage: StageSplat}}, "both write"},
+		{"missing required input", stepsOf(StageColmap, StagePTv3), "no stage of the pipeline produces"},
+		{"required input written later", []Step{{Stage: StageColmap, Output: OutputSegmentedPclDirUrl}, {Stage: StagePTv3, Output: OutputPclColmapUrl}, {Stage: StageSplat}}, "has to run after splat"},
+		{"optional input written later", stepsOf(StageColmap, StageSplat, StageSaga, StagePTv3), "has to run after ptv3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewDefinition(test.steps)
+			if len(test.want) == 0 && err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if len(test.want) > 0 && (err == nil || !strings.Contains(err.Error(), test.want)) {
+				t.Fatalf("got error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestNewDefinitionFillsInDefaults(t *testing.T) {
+	def, err := NewDefinition([]Step{
+		{Stage: StageColmap},
+		{Stage: StageSplat, Queue: "gpu.splat"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Step{
+		{Stage: StageColmap, Queue: StageColmap.Queue(), Output: OutputPclColmapUrl},
+		{Stage: StageSplat, Queue: "gpu.splat", Output: OutputSplatUrl},
+	}
+	if len(def.Steps) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(def.Steps), len(want))
+	}
+	for i, step := range def.Steps {
+		if step != want[i] {
+			t.Errorf("step %d: got %+v, want %+v", i, step, want[i])
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package pipeline
+
+import "fmt"
+
+// Stage is a unit of GPU work performed by the workers.
+type Stage string
+
+const (
+	StageColmap Stage = "colmap"
+	StageSplat  Stage = "splat"
+	StagePTv3   Stage = "ptv3"
+	StageSaga   Stage = "saga"
+)
+
+// Stages lists every stage in the order the default pipeline runs them.
+var Stages = []Stage{StageColmap, StageSplat, StagePTv3, StageSaga}
+
+// Output names the assets column a stage stores the url of its result in.
+type Output string
+
+const (
+	OutputPclColmapUrl         Output = "pclColmapUrl"
+	OutputSplatUrl             Output = "splatUrl"
+	OutputSegmentedPclDirUrl   Output = "segmentedPclDirUrl"
+	OutputSegmentedSplatDirUrl Output = "segmentedSplatDirUrl"
+)
+
+func (output Output) IsValid() bool {
+	switch output {
+	case OutputPclColmapUrl, OutputSplatUrl, OutputSegmentedPclDirUrl, OutputSegmentedSplatDirUrl:
+		return true
+	}
+
+	return false
+}
+
+var stageStates = map[Stage]State{
+	StageColmap: StateGeneratingPointCloud,
+	StageSplat:  StateGeneratingSplat,
+	StagePTv3:   StateProcessingPTv3,
+	StageSaga:   StateProcessingSaga,
+}
+
+// ResultsQueue is where workers publish stage results instead of calling the
+// HTTP callbacks.
+const ResultsQueue = "process.results"
+
+// ControlExchange is the fanout exchange cancel messages are broadcast on.
+// Every worker binds its own queue to it, so all of them see each message.
+const ControlExchange = "control"
+
+var stageQueues = map[Stage]string{
+	StageColmap: "process",
+	StageSplat:  "process.splat",
+	StagePTv3:   "process.ptv3",
+	StageSaga:   "process.saga",
+}
+
+var stageOutputs = map[Stage]Output{
+	StageColmap: OutputPclColmapUrl,
+	StageSplat:  OutputSplatUrl,
+	StagePTv3:   OutputSegmentedPclDirUrl,
+	StageSaga:   OutputSegmentedSplatDirUrl,
+}
+
+// stageInput is an output a stage reads. A required input has to be produced
+// by an earlier step of the pipeline, an optional one only has to be produced
+// earlier if the pipeline produces it at all.
+type stageInput struct {
+	output   Output
+	required bool
+}
+
+var stageInputs = map[Stage][]stageInput{
+	StagePTv3: {{output: OutputSplatUrl, required: true}},
+	StageSaga: {{output: OutputSplatUrl, required: true}, {output: OutputSegmentedPclDirUrl}},
+}
+
+func ParseStage(value string) (Stage, error) {
+	stage := Stage(value)
+	if !stage.IsValid() {
+		return "", fmt.Errorf("unknown pipeline stage %q", value)
+	}
+
+	return stage, nil
+}
+
+func (stage Stage) IsValid() bool {
+	_, ok := stageStates[stage]
+	return ok
+}
+
+// StageOf returns the stage that runs while an asset is in the state.
+func StageOf(state State) (Stage, bool) {
+	for stage, stageState := range stageStates {
+		if stageState == state {
+			return stage, true
+		}
+	}
+
+	return "", false
+}
+
+// State returns the status an asset has while the stage is running.
+func (stage Stage) State() State {
+	return stageStates[stage]
+}
+
+// Queue returns the RabbitMQ queue the workers consume the stage from unless
+// a pipeline definition names another one.
+func (stage Stage) Queue() string {
+	return stageQueues[stage]
+}
+
+// Output returns the column the stage stores its result in unless a pipeline
+// definition names another one.
+func (stage Stage) Output() Output {
+	return stageOutputs[stage]
+}
@@ -0,0 +1,43 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+)
+
+// State is the processing status of an asset as stored in assets.status.
+type State string
+
+const (
+	// StateDraft is an asset whose photos are still being uploaded. It
+	// starts its pipeline once the upload is finalized.
+	StateDraft                State = "draft"
+	StateCreated              State = "created"
+	StateGeneratingPointCloud State = "generating sparse point cloud"
+	StateGeneratingSplat      State = "generating 3d splat"
+	StateProcessingPTv3       State = "processing ptv3"
+	StateProcessingSaga       State = "processing saga"
+	StateCompleted            State = "completed"
+	StateFailed               State = "failed"
+	StateCancelled            State = "cancelled"
+)
+
+var ErrIllegalTransition = errors.New("illegal status transition")
+
+// ErrCancelled is returned for work reported on a cancelled asset, e.g. a
+// result a worker published before it saw the cancel message.
+var ErrCancelled = fmt.Errorf("%w: asset was cancelled", ErrIllegalTransition)
+
+func (state State) IsValid() bool {
+	switch state {
+	case StateDraft, StateCreated, StateGeneratingPointCloud, StateGeneratingSplat, StateProcessingPTv3,
+		StateProcessingSaga, StateCompleted, StateFailed, StateCancelled:
+		return true
+	}
+
+	return false
+}
+
+func (state State) IsTerminal() bool {
+	return state == StateCompleted || state == StateFailed || state == StateCancelled
+}
@@ -0,0 +1,63 @@
+package rabbitmq
+
+import "context"
+
+// Handler processes the body of a single delivery. Returning an error rejects
+// the message to the dead-letter queue.
+type Handler func(ctx context.Context, body []byte) error
+
+// DeadLetter is a message that was rejected by its consumer or expired.
+type DeadLetter struct {
+	// Queue is the queue the message was originally published to.
+	Queue  string
+	Reason string
+	Error  string
+	Count  int64
+	Body   []byte
+}
+
+// DeadLetterHandler processes a message from a dead-letter queue.
+type DeadLetterHandler func(ctx context.Context, letter DeadLetter) error
+
+// MaxPriority is the highest message priority the queues support. Messages
+// with a higher priority are delivered before those with a lower one.
+const MaxPriority = 10
+
+// Priority clamps p to the priorities the queues support.
+func Priority(p int) uint8 {
+	if p < 0 {
+		return 0
+	}
+	if p > MaxPriority {
+		return MaxPriority
+	}
+
+	return uint8(p)
+}
+
+// Publisher publishes messages to a queue, or to every queue bound to a
+// fanout exchange.
+type Publisher interface {
+	PublishEvent(queue string, msg []byte, priority uint8) error
+	Broadcast(exchange string, msg []byte) error
+}
+
+// Consumer delivers the messages of a queue, or a copy of those broadcast to
+// a fanout exchange, to a handler.
+type Consumer interface {
+	Consume(queue string, handler Handler) error
+	ConsumeDeadLetters(queue string, handler DeadLetterHandler) error
+	ConsumeBroadcast(exchange string, handler Handler) error
+}
+
+// Broker is the message broker the server talks to. RabbitMq implements it
+// for production and MemoryBroker for tests.
+type Broker interface {
+	Publisher
+	Consumer
+}
+
+var (
+	_ Broker = (*RabbitMq)(nil)
+	_ Broker = (*MemoryBroker)(nil)
+)
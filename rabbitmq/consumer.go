@@ -0,0 +1,320 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// deadLetterRetryDelay slows down redelivery of dead letters whose handler
+// failed, e.g. while the database is unavailable.
+const deadLetterRetryDelay = time.Second
+
+type subscription struct {
+	// queue is the consumed queue, or the exchange of a broadcast
+	// subscription.
+	queue             string
+	broadcast         bool
+	handler           Handler
+	deadLetterHandler DeadLetterHandler
+}
+
+// DeadLetterExchange is the exchange rejected and expired messages are
+// routed to. RabbitMQ sends them there through the dead-letter policy, see
+// README.md, keeping the name of their queue as routing key.
+const DeadLetterExchange = "dead-letters"
+
+// DeadLetterQueue returns the name of the queue rejected messages of queue end
+// up in.
+func DeadLetterQueue(queue string) string {
+	return queue + ".dead"
+}
+
+// queueArgs are the arguments queues are created with: rejected messages go to
+// the dead-letter exchange under the name of the queue, which routes them to
+// its dead-letter queue.
+func queueArgs() amqp091.Table {
+	return amqp091.Table{
+		"x-dead-letter-exchange": DeadLetterExchange,
+		"x-max-priority":         MaxPriority,
+	}
+}
+
+// declareQueue makes sure the queue and its dead-letter queue exist, the
+// latter bound to the dead-letter exchange. A missing queue is created with
+// queueArgs. RabbitMQ refuses to redeclare an existing queue with different
+// arguments, so such a queue is used as it is and a warning is logged, since
+// it may ignore priorities and its rejected messages are only dead-lettered if
+// the dead-letters policy applies to it.
+func declareQueue(conn *amqp091.Connection, queue string) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	err = ch.ExchangeDeclare(DeadLetterExchange, amqp091.ExchangeDirect, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = ch.QueueDeclare(DeadLetterQueue(queue), true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	err = ch.QueueBind(DeadLetterQueue(queue), queue, DeadLetterExchange, false, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = ch.QueueDeclare(queue, true, false, false, false, queueArgs())
+	if err == nil {
+		return nil
+	}
+
+	var amqpErr *amqp091.Error
+	if !errors.As(err, &amqpErr) || amqpErr.Code != amqp091.PreconditionFailed {
+		return err
+	}
+
+	log.Printf("WARNING: queue %s exists without the arguments %v; it may ignore priorities and messages it rejects are dropped unless the %s policy applies to it, see README.md", queue, queueArgs(), DeadLetterExchange)
+	return nil
+}
+
+// Consume subscribes to the queue on a dedicated channel and hands every
+// delivery to the handler. Messages are acked only after the handler succeeded
+// and are nacked otherwise, which routes them to the dead-letter queue as long
+// as the queue has a dead-letter exchange, see declareQueue. The subscription is
+// renewed whenever the connection is re-established.
+func (rmq *RabbitMq) Consume(queue string, handler Handler) error {
+	return rmq.addSubscription(subscription{queue: queue, handler: handler})
+}
+
+// ConsumeDeadLetters subscribes to the dead-letter queue of queue. Letters are
+// acked once the handler succeeded and redelivered otherwise.
+func (rmq *RabbitMq) ConsumeDeadLetters(queue string, handler DeadLetterHandler) error {
+	return rmq.addSubscription(subscription{queue: queue, deadLetterHandler: handler})
+}
+
+// ConsumeBroadcast subscribes to the fanout exchange through an exclusive queue
+// of its own, so every process consuming the exchange receives a copy of each
+// message. The queue goes away with the connection, so messages broadcast
+// while the process is disconnected are missed.
+func (rmq *RabbitMq) ConsumeBroadcast(exchange string, handler Handler) error {
+	return rmq.addSubscription(subscription{queue: exchange, broadcast: true, handler: handler})
+}
+
+func (rmq *RabbitMq) addSubscription(c subscription) error {
+	conn, err := rmq.connection()
+	if err != nil {
+		return err
+	}
+
+	err = rmq.subscribe(conn, c)
+	if err != nil {
+		return err
+	}
+
+	rmq.mu.Lock()
+	rmq.subscriptions = append(rmq.subscriptions, c)
+	rmq.mu.Unlock()
+
+	return nil
+}
+
+func (rmq *RabbitMq) subscribe(conn *amqp091.Connection, c subscription) error {
+	if !c.broadcast {
+		err := declareQueue(conn, c.queue)
+		if err != nil {
+			return err
+		}
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+
+	queue := c.queue
+	if c.deadLetterHandler != nil {
+		queue = DeadLetterQueue(c.queue)
+	}
+	if c.broadcast {
+		queue, err = declareBroadcastQueue(ch, c.queue)
+		if err != nil {
+			ch.Close()
+			return err
+		}
+	}
+
+	closed := ch.NotifyClose(make(chan *amqp091.Error, 1))
+	cancelled := ch.NotifyCancel(make(chan string, 1))
+
+	deliveries, err := consume(ch, queue)
+	if err != nil {
+		ch.Close()
+		return err
+	}
+
+	go rmq.watchSubscription(conn, ch, c, closed, cancelled)
+
+	// deliveries is closed together with the channel or when the consumer is
+	// cancelled, after which watchSubscription or the reconnect renews it
+	go func() {
+		for delivery := range deliveries {
+			if c.deadLetterHandler != nil {
+				handleDeadLetter(c, delivery)
+				continue
+			}
+
+			err := c.handler(context.Background(), delivery.Body)
+			if err != nil {
+				log.Printf("can't process message from %s: %v", c.queue, err)
+				if err := delivery.Nack(false, false); err != nil {
+					log.Printf("can't nack message from %s: %v", c.queue, err)
+				}
+				continue
+			}
+
+			if err := delivery.Ack(false); err != nil {
+				log.Printf("can't ack message from %s: %v", c.queue, err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// watchSubscription renews the subscription when the broker closes its
+// channel, e.g. after a failed ack, or cancels its consumer, e.g. because the
+// queue was deleted. Subscriptions lost along with the connection are renewed
+// by the reconnect instead.
+func (rmq *RabbitMq) watchSubscription(conn *amqp091.Connection, ch *amqp091.Channel, c subscription, closed chan *amqp091.Error, cancelled chan string) {
+	select {
+	case reason := <-closed:
+		if reason == nil {
+			return
+		}
+		log.Printf("rabbitmq channel consuming %s closed: %v", c.queue, reason)
+	case _, ok := <-cancelled:
+		if !ok {
+			// the channel is shutting down and reports why on closed
+			reason := <-closed
+			if reason == nil {
+				return
+			}
+			log.Printf("rabbitmq channel consuming %s closed: %v", c.queue, reason)
+			break
+		}
+		log.Printf("rabbitmq cancelled the consumer of %s", c.queue)
+		ch.Close()
+	}
+
+	rmq.resubscribe(conn, c)
+}
+
+// resubscribe renews the subscription on conn with the same backoff as the
+// reconnect. It gives up once conn is closed, since the reconnect subscribes
+// again on the new connection.
+func (rmq *RabbitMq) resubscribe(conn *amqp091.Connection, c subscription) {
+	delay := minReconnectDelay
+	for {
+		time.Sleep(delay)
+
+		rmq.mu.RLock()
+		stale := rmq.closed || rmq.conn != conn || conn.IsClosed()
+		rmq.mu.RUnlock()
+		if stale {
+			return
+		}
+
+		err := rmq.subscribe(conn, c)
+		if err == nil {
+			log.Printf("rabbitmq resubscribed to %s", c.queue)
+			return
+		}
+
+		log.Printf("can't resubscribe to %s, retrying in %s: %v", c.queue, delay, err)
+		delay = nextReconnectDelay(delay)
+	}
+}
+
+func handleDeadLetter(c subscription, delivery amqp091.Delivery) {
+	err := c.deadLetterHandler(context.Background(), parseDeadLetter(c.queue, delivery))
+	if err != nil {
+		log.Printf("can't process dead letter from %s: %v", c.queue, err)
+		time.Sleep(deadLetterRetryDelay)
+		if err := delivery.Nack(false, true); err != nil {
+			log.Printf("can't nack dead letter from %s: %v", c.queue, err)
+		}
+		return
+	}
+
+	if err := delivery.Ack(false); err != nil {
+		log.Printf("can't ack dead letter from %s: %v", c.queue, err)
+	}
+}
+
+// parseDeadLetter reads why the message was dead-lettered from the x-death
+// header RabbitMQ adds and the optional x-error header set by the worker.
+func parseDeadLetter(queue string, delivery amqp091.Delivery) DeadLetter {
+	letter := DeadLetter{
+		Queue:  queue,
+		Reason: "unknown",
+		Count:  1,
+		Body:   delivery.Body,
+	}
+
+	if deaths, ok := delivery.Headers["x-death"].([]any); ok && len(deaths) > 0 {
+		if death, ok := deaths[0].(amqp091.Table); ok {
+			if reason, ok := death["reason"].(string); ok {
+				letter.Reason = reason
+			}
+			if count, ok := death["count"].(int64); ok {
+				letter.Count = count
+			}
+		}
+	}
+
+	if reason, ok := delivery.Headers["x-error"].(string); ok {
+		letter.Error = reason
+	}
+
+	return letter
+}
+
+// declareBroadcastQueue declares an exclusive queue bound to the fanout
+// exchange and returns the name the broker generated for it.
+func declareBroadcastQueue(ch *amqp091.Channel, exchange string) (string, error) {
+	err := ch.ExchangeDeclare(exchange, amqp091.ExchangeFanout, true, false, false, false, nil)
+	if err != nil {
+		return "", err
+	}
+
+	queue, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return "", err
+	}
+
+	err = ch.QueueBind(queue.Name, "", exchange, false, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return queue.Name, nil
+}
+
+// consume starts consuming from the queue with manual acks.
+func consume(ch *amqp091.Channel, queue string) (<-chan amqp091.Delivery, error) {
+	// handle one message at a time so unacked results are not piled up here
+	err := ch.Qos(1, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return ch.Consume(queue, "", false, false, false, false, nil)
+}
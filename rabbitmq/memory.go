@@ -0,0 +1,169 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Message is a message recorded by MemoryBroker.
+type Message struct {
+	Queue    string
+	Exchange string
+	Priority uint8
+	Body     []byte
+}
+
+// MemoryBroker is an in-process Broker that records every published message
+// and lets tests feed messages, such as worker results, to the consumers.
+type MemoryBroker struct {
+	mu          sync.Mutex
+	published   []Message
+	handlers    map[string]Handler
+	deadLetters map[string]DeadLetterHandler
+	broadcasts  map[string][]Handler
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		handlers:    make(map[string]Handler),
+		deadLetters: make(map[string]DeadLetterHandler),
+		broadcasts:  make(map[string][]Handler),
+	}
+}
+
+func (broker *MemoryBroker) PublishEvent(queue string, msg []byte, priority uint8) error {
+	broker.record(Message{Queue: queue, Priority: priority, Body: msg})
+	return nil
+}
+
+// Broadcast records the message and hands it to every consumer of the
+// exchange synchronously. Like with RabbitMQ, the publisher does not learn
+// whether the consumers succeeded.
+func (broker *MemoryBroker) Broadcast(exchange string, msg []byte) error {
+	broker.record(Message{Exchange: exchange, Body: msg})
+
+	broker.mu.Lock()
+	handlers := broker.broadcasts[exchange]
+	broker.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(context.Background(), msg)
+	}
+
+	return nil
+}
+
+func (broker *MemoryBroker) record(msg Message) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	body := make([]byte, len(msg.Body))
+	copy(body, msg.Body)
+	msg.Body = body
+	broker.published = append(broker.published, msg)
+}
+
+func (broker *MemoryBroker) Consume(queue string, handler Handler) error {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	if _, ok := broker.handlers[queue]; ok {
+		return fmt.Errorf("queue %s is already consumed", queue)
+	}
+	broker.handlers[queue] = handler
+
+	return nil
+}
+
+func (broker *MemoryBroker) ConsumeDeadLetters(queue string, handler DeadLetterHandler) error {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	if _, ok := broker.deadLetters[queue]; ok {
+		return fmt.Errorf("dead letters of queue %s are already consumed", queue)
+	}
+	broker.deadLetters[queue] = handler
+
+	return nil
+}
+
+// ConsumeBroadcast adds a consumer of the exchange. Each consumer stands for a
+// process with a queue of its own, so all of them receive every message.
+func (broker *MemoryBroker) ConsumeBroadcast(exchange string, handler Handler) error {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	broker.broadcasts[exchange] = append(broker.broadcasts[exchange], handler)
+
+	return nil
+}
+
+// Published returns the messages published to the queue so far, oldest first.
+func (broker *MemoryBroker) Published(queue string) []Message {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	messages := []Message{}
+	for _, msg := range broker.published {
+		if msg.Exchange == "" && msg.Queue == queue {
+			messages = append(messages, msg)
+		}
+	}
+
+	return messages
+}
+
+// Broadcasted returns the messages broadcast to the exchange so far, oldest
+// first.
+func (broker *MemoryBroker) Broadcasted(exchange string) []Message {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	messages := []Message{}
+	for _, msg := range broker.published {
+		if msg.Exchange == exchange {
+			messages = append(messages, msg)
+		}
+	}
+
+	return messages
+}
+
+// Deliver hands the message to the consumer of the queue synchronously and
+// returns the error of its handler. A rejected message is dead-lettered like
+// RabbitMQ would.
+func (broker *MemoryBroker) Deliver(ctx context.Context, queue string, body []byte) error {
+	broker.mu.Lock()
+	handler, ok := broker.handlers[queue]
+	broker.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("queue %s has no consumer", queue)
+	}
+
+	err := handler(ctx, body)
+	if err != nil {
+		letter := DeadLetter{Queue: queue, Reason: "rejected", Error: err.Error(), Count: 1, Body: body}
+		if dlErr := broker.DeadLetter(ctx, letter); dlErr != nil {
+			return fmt.Errorf("%v, dead letter err: %v", err, dlErr)
+		}
+	}
+
+	return err
+}
+
+// DeadLetter hands the letter to the dead-letter consumer of its queue, e.g.
+// to simulate a worker rejecting a message. Letters of queues without a
+// dead-letter consumer are dropped.
+func (broker *MemoryBroker) DeadLetter(ctx context.Context, letter DeadLetter) error {
+	broker.mu.Lock()
+	handler, ok := broker.deadLetters[letter.Queue]
+	broker.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return handler(ctx, letter)
+}
@@ -2,52 +2,258 @@ package rabbitmq
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	// channelPoolSize is the number of idle publishing channels kept open.
+	channelPoolSize = 8
+	// publishTimeout bounds how long PublishEvent waits for the broker to
+	// confirm a message.
+	publishTimeout = 5 * time.Second
+	// minReconnectDelay and maxReconnectDelay bound the backoff between
+	// reconnect attempts.
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+var ErrNotConnected = errors.New("rabbitmq is not connected")
+
+// RabbitMq keeps a connection to the broker and reconnects with backoff when
+// it is lost. Publishing channels are pooled since an amqp channel must not be
+// used by several goroutines at once.
 type RabbitMq struct {
-	Channel *amqp091.Channel
+	source string
+	queues []string
+
+	mu            sync.RWMutex
+	conn          *amqp091.Connection
+	idle          chan *amqp091.Channel
+	subscriptions []subscription
+	closed        bool
 }
 
-func NewRabbitMq(source string) (*RabbitMq, error) {
-	con, err := amqp091.Dial(source)
+// NewRabbitMq connects to the broker and declares the given queues along with
+// their dead-letter queues. The queues are declared again every time the
+// connection is re-established.
+func NewRabbitMq(source string, queues ...string) (*RabbitMq, error) {
+	rmq := &RabbitMq{
+		source: source,
+		queues: queues,
+		idle:   make(chan *amqp091.Channel, channelPoolSize),
+	}
+
+	err := rmq.connect()
 	if err != nil {
 		return nil, err
 	}
 
-	rcon, err := con.Channel()
+	return rmq, nil
+}
+
+// connect dials the broker, declares the queues, resubscribes the consumers
+// and starts watching the new connection.
+func (rmq *RabbitMq) connect() error {
+	conn, err := amqp091.Dial(rmq.source)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	for _, queue := range rmq.queues {
+		err := declareQueue(conn, queue)
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("can't declare queue %s: %w", queue, err)
+		}
+	}
+
+	rmq.mu.Lock()
+	rmq.conn = conn
+	subscriptions := rmq.subscriptions
+	rmq.mu.Unlock()
+
+	for _, c := range subscriptions {
+		err := rmq.subscribe(conn, c)
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("can't resubscribe to %s: %w", c.queue, err)
+		}
+	}
+
+	go rmq.watch(conn.NotifyClose(make(chan *amqp091.Error, 1)))
+
+	return nil
+}
+
+// watch waits for the connection to close and reconnects unless it was closed
+// on purpose.
+func (rmq *RabbitMq) watch(notify chan *amqp091.Error) {
+	reason, ok := <-notify
+	if !ok || reason == nil {
+		return
+	}
+
+	log.Printf("rabbitmq connection lost: %v", reason)
+
+	delay := minReconnectDelay
+	for {
+		rmq.mu.RLock()
+		closed := rmq.closed
+		rmq.mu.RUnlock()
+		if closed {
+			return
+		}
+
+		time.Sleep(delay)
+
+		err := rmq.connect()
+		if err == nil {
+			log.Printf("rabbitmq reconnected")
+			return
+		}
+
+		log.Printf("can't reconnect to rabbitmq, retrying in %s: %v", delay, err)
+		delay = nextReconnectDelay(delay)
+	}
+}
+
+// nextReconnectDelay doubles the delay up to maxReconnectDelay.
+func nextReconnectDelay(delay time.Duration) time.Duration {
+	delay *= 2
+	if delay > maxReconnectDelay {
+		return maxReconnectDelay
+	}
+
+	return delay
+}
+
+// Close closes the connection without reconnecting.
+func (rmq *RabbitMq) Close() error {
+	rmq.mu.Lock()
+	defer rmq.mu.Unlock()
+
+	rmq.closed = true
+	if rmq.conn == nil {
+		return nil
+	}
+
+	return rmq.conn.Close()
+}
+
+func (rmq *RabbitMq) connection() (*amqp091.Connection, error) {
+	rmq.mu.RLock()
+	defer rmq.mu.RUnlock()
+
+	if rmq.conn == nil || rmq.conn.IsClosed() {
+		return nil, ErrNotConnected
 	}
 
-	return &RabbitMq{
-		Channel: rcon,
-	}, nil
+	return rmq.conn, nil
+}
+
+// acquire takes an idle publishing channel from the pool or opens a new one
+// in confirm mode.
+func (rmq *RabbitMq) acquire() (*amqp091.Channel, error) {
+	for {
+		select {
+		case ch := <-rmq.idle:
+			if !ch.IsClosed() {
+				return ch, nil
+			}
+		default:
+			conn, err := rmq.connection()
+			if err != nil {
+				return nil, err
+			}
+
+			ch, err := conn.Channel()
+			if err != nil {
+				return nil, err
+			}
+
+			err = ch.Confirm(false)
+			if err != nil {
+				ch.Close()
+				return nil, err
+			}
+
+			return ch, nil
+		}
+	}
 }
 
-func (rmq *RabbitMq) PublishEvent(queue string, msg []byte) error {
-	// create channel
-	q, err := rmq.Channel.QueueDeclare(queue, true, false, false, false, nil)
+// release puts the channel back into the pool, closing it when the pool is
+// already full.
+func (rmq *RabbitMq) release(ch *amqp091.Channel) {
+	if ch.IsClosed() {
+		return
+	}
+
+	select {
+	case rmq.idle <- ch:
+	default:
+		ch.Close()
+	}
+}
+
+// PublishEvent publishes a persistent message with the given priority to the
+// queue and returns once the broker confirmed it.
+func (rmq *RabbitMq) PublishEvent(queue string, msg []byte, priority uint8) error {
+	return rmq.publish("", queue, msg, priority)
+}
+
+// Broadcast publishes a persistent message to the fanout exchange, declaring
+// it first, and returns once the broker confirmed it. Every queue bound to the
+// exchange receives a copy, so each worker can watch it with its own queue.
+func (rmq *RabbitMq) Broadcast(exchange string, msg []byte) error {
+	return rmq.publish(exchange, "", msg, 0)
+}
+
+func (rmq *RabbitMq) publish(exchange string, key string, msg []byte, priority uint8) error {
+	ch, err := rmq.acquire()
 	if err != nil {
 		return err
 	}
+	defer rmq.release(ch)
 
-	// create context
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if exchange != "" {
+		err = ch.ExchangeDeclare(exchange, amqp091.ExchangeFanout, true, false, false, false, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
 	defer cancel()
 
 	publishedMsg := amqp091.Publishing{
 		ContentType:  "application/json",
-		DeliveryMode: 2,
+		DeliveryMode: amqp091.Persistent,
+		Priority:     priority,
 		Body:         msg,
 	}
 
-	err = rmq.Channel.PublishWithContext(ctx, "", q.Name, false, false, publishedMsg)
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx, exchange, key, false, false, publishedMsg)
 	if err != nil {
 		return err
 	}
 
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		// the confirmation may still arrive on this channel later, so do not
+		// hand it to another publisher
+		ch.Close()
+		return err
+	}
+	if !acked {
+		return fmt.Errorf("broker rejected message to %s%s", exchange, key)
+	}
+
 	return nil
 }
@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	// filesPrefix is where the storage server serves the uploaded files from.
+	filesPrefix = "/files"
+	// offsetHeader tells the storage server where the written data starts.
+	offsetHeader = "Upload-Offset"
+)
+
+// HTTPStorage talks to the storage server. Every storage server serves the
+// files under /files, honouring Range and HEAD requests, and answers GET
+// /thumbnail/... with the JSON url of a photo of the directory. The other
+// operations need endpoints a storage server only has when it is configured
+// with the capability:
+//
+//   - stat: GET /stat/... answers with the JSON FileInfo of the path
+//   - list: GET /list/... answers with {"files": [...]} for the directory
+//   - write: PATCH /files/... stores the body at the offset in the
+//     Upload-Offset header, creating the file and its directories
+//   - delete: DELETE /files/... removes the file or directory
+type HTTPStorage struct {
+	baseUrl      string
+	signingKey   string
+	capabilities map[Capability]bool
+	client       *http.Client
+	// uploadClient streams writes, which take as long as the data takes to
+	// arrive, so only the wait for the response is limited.
+	uploadClient *http.Client
+}
+
+func NewHTTPStorage(baseUrl string, signingKey string, capabilities []Capability) *HTTPStorage {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = requestTimeout
+
+	storage := &HTTPStorage{
+		baseUrl:      strings.TrimSuffix(baseUrl, "/"),
+		signingKey:   signingKey,
+		capabilities: make(map[Capability]bool),
+		client:       &http.Client{Timeout: requestTimeout},
+		uploadClient: &http.Client{Transport: transport},
+	}
+	for _, capability := range capabilities {
+		storage.capabilities[capability] = true
+	}
+
+	return storage
+}
+
+type listResponse struct {
+	Files []FileInfo `json:"files"`
+}
+
+type thumbnailResponse struct {
+	Url string `json:"url"`
+}
+
+func (storage *HTTPStorage) Supports(capability Capability) bool {
+	return storage.capabilities[capability]
+}
+
+// unsupported is the error of an operation that needs a capability the
+// storage server lacks.
+func unsupported(capability Capability) error {
+	return fmt.Errorf("%w: the storage server is not configured with %s", ErrUnsupported, capability)
+}
+
+func (storage *HTTPStorage) Stat(ctx context.Context, path string) (FileInfo, error) {
+	if !storage.Supports(CapabilityStat) {
+		return storage.head(ctx, path)
+	}
+
+	var info FileInfo
+	err := storage.getJSON(ctx, "/stat", path, &info)
+	return info, err
+}
+
+// head describes the file at the path from the headers the storage server
+// sends for it. It cannot tell directories apart from files.
+func (storage *HTTPStorage) head(ctx context.Context, path string) (FileInfo, error) {
+	info := FileInfo{Path: cleanPath(path)}
+	path, err := relativePath(path)
+	if err != nil {
+		return info, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, storage.baseUrl+filesPrefix+path, nil)
+	if err != nil {
+		return info, err
+	}
+
+	resp, err := storage.client.Do(req)
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return info, fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if err := checkStatus(resp); err != nil {
+		return info, err
+	}
+
+	info.Size = resp.ContentLength
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = modTime
+	}
+
+	return info, nil
+}
+
+func (storage *HTTPStorage) List(ctx context.Context, dir string) ([]FileInfo, error) {
+	if !storage.Supports(CapabilityList) {
+		return nil, unsupported(CapabilityList)
+	}
+
+	var res listResponse
+	err := storage.getJSON(ctx, "/list", dir, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	files := res.Files
+	if files == nil {
+		files = []FileInfo{}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	return files, nil
+}
+
+func (storage *HTTPStorage) Thumbnail(ctx context.Context, dir string) (string, error) {
+	var res thumbnailResponse
+	err := storage.getJSON(ctx, "/thumbnail", dir, &res)
+	return res.Url, err
+}
+
+func (storage *HTTPStorage) Peek(ctx context.Context, path string, n int) ([]byte, error) {
+	path, err := relativePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, storage.baseUrl+filesPrefix+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", n-1))
+
+	resp, err := storage.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	// a server that ignores the range sends the whole file
+	return io.ReadAll(io.LimitReader(resp.Body, int64(n)))
+}
+
+func (storage *HTTPStorage) Write(ctx context.Context, path string, offset int64, data io.Reader) (int64, error) {
+	if !storage.Supports(CapabilityWrite) {
+		return 0, unsupported(CapabilityWrite)
+	}
+
+	path, err := relativePath(path)
+	if err != nil {
+		return 0, err
+	}
+
+	body := &countingReader{reader: data}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, storage.baseUrl+filesPrefix+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(offsetHeader, strconv.FormatInt(offset, 10))
+
+	resp, err := storage.uploadClient.Do(req)
+	if err != nil {
+		return storage.persisted(ctx, path, offset, body.n), err
+	}
+	defer resp.Body.Close()
+
+	err = checkStatus(resp)
+	if err != nil {
+		return storage.persisted(ctx, path, offset, body.n), err
+	}
+
+	return body.n, nil
+}
+
+// persisted tells how much of a failed write the storage server kept, going
+// by the size of the file after it. At most sent bytes count, and none when
+// the size cannot be read.
+func (storage *HTTPStorage) persisted(ctx context.Context, path string, offset int64, sent int64) int64 {
+	info, err := storage.Stat(context.WithoutCancel(ctx), filesPrefix+path)
+	if err != nil || info.Size <= offset {
+		return 0
+	}
+
+	return min(info.Size-offset, sent)
+}
+
+func (storage *HTTPStorage) Delete(ctx context.Context, path string) error {
+	if !storage.Supports(CapabilityDelete) {
+		return unsupported(CapabilityDelete)
+	}
+
+	path, err := relativePath(path)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, storage.baseUrl+filesPrefix+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := storage.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	return checkStatus(resp)
+}
+
+func (storage *HTTPStorage) SignedURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	return signURL(storage.baseUrl, storage.signingKey, path, time.Now().Add(expires))
+}
+
+// getJSON decodes the answer of the endpoint for the path.
+func (storage *HTTPStorage) getJSON(ctx context.Context, endpoint string, path string, v any) error {
+	path, err := relativePath(path)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, storage.baseUrl+endpoint+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := storage.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "application/json") {
+		return fmt.Errorf("expected JSON response from storage, got: %s", contentType)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// relativePath strips the /files prefix the storage server serves the path
+// under, which its other endpoints leave out.
+func relativePath(path string) (string, error) {
+	path = cleanPath(path)
+	if !strings.HasPrefix(path, filesPrefix+"/") {
+		return "", fmt.Errorf("%q is not a storage path", path)
+	}
+
+	return strings.TrimPrefix(path, filesPrefix), nil
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("storage responded with %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
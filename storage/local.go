@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// imageExtensions are the photo formats a thumbnail is picked from.
+var imageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+}
+
+// LocalStorage keeps the files in a directory, mapping /files/<path> to
+// <root>/<path>. Its urls point at baseUrl, which is expected to serve the
+// directory the way the storage server would.
+type LocalStorage struct {
+	root       string
+	baseUrl    string
+	signingKey string
+}
+
+func NewLocalStorage(root string, baseUrl string, signingKey string) (*LocalStorage, error) {
+	if len(root) == 0 {
+		return nil, fmt.Errorf("local storage needs a directory")
+	}
+
+	err := os.MkdirAll(root, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{
+		root:       root,
+		baseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		signingKey: signingKey,
+	}, nil
+}
+
+// Supports reports true for every capability, since the directory offers
+// them all.
+func (storage *LocalStorage) Supports(capability Capability) bool {
+	return true
+}
+
+func (storage *LocalStorage) Stat(ctx context.Context, p string) (FileInfo, error) {
+	name, err := storage.filename(p)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return FileInfo{}, notFound(err, p)
+	}
+
+	return fileInfo(cleanPath(p), info), nil
+}
+
+func (storage *LocalStorage) List(ctx context.Context, dir string) ([]FileInfo, error) {
+	name, err := storage.filename(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(name)
+	if err != nil {
+		return nil, notFound(err, dir)
+	}
+
+	// ReadDir sorts the entries by name already
+	files := []FileInfo{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, fileInfo(path.Join(cleanPath(dir), entry.Name()), info))
+	}
+
+	return files, nil
+}
+
+// Thumbnail returns the url of the first photo in the directory.
+func (storage *LocalStorage) Thumbnail(ctx context.Context, dir string) (string, error) {
+	files, err := storage.List(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		if !file.IsDir && imageExtensions[strings.ToLower(path.Ext(file.Path))] {
+			return storage.baseUrl + file.Path, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: no photo in %s", ErrNotFound, cleanPath(dir))
+}
+
+func (storage *LocalStorage) Peek(ctx context.Context, p string, n int) ([]byte, error) {
+	name, err := storage.filename(p)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, notFound(err, p)
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, int64(n)))
+}
+
+func (storage *LocalStorage) Write(ctx context.Context, p string, offset int64, data io.Reader) (int64, error) {
+	name, err := storage.filename(p)
+	if err != nil {
+		return 0, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(name), 0o755)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(file, data)
+	if err != nil {
+		return n, err
+	}
+
+	return n, file.Close()
+}
+
+func (storage *LocalStorage) Delete(ctx context.Context, p string) error {
+	name, err := storage.filename(p)
+	if err != nil {
+		return err
+	}
+
+	// RemoveAll is a no-op for a missing path
+	return os.RemoveAll(name)
+}
+
+func (storage *LocalStorage) SignedURL(ctx context.Context, p string, expires time.Duration) (string, error) {
+	return signURL(storage.baseUrl, storage.signingKey, p, time.Now().Add(expires))
+}
+
+// filename returns where the file at the storage path is kept on disk.
+func (storage *LocalStorage) filename(p string) (string, error) {
+	rel, err := relativePath(p)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(storage.root, filepath.FromSlash(rel)), nil
+}
+
+func fileInfo(p string, info fs.FileInfo) FileInfo {
+	return FileInfo{
+		Path:    p,
+		Size:    info.Size(),
+		IsDir:   info.IsDir(),
+		ModTime: info.ModTime(),
+	}
+}
+
+func notFound(err error, p string) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, cleanPath(p))
+	}
+
+	return err
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned for a path the storage has no file or directory at.
+var ErrNotFound = errors.New("file not found")
+
+// ErrUnsupported is returned for an operation the storage does not offer.
+var ErrUnsupported = errors.New("operation is not supported by the storage")
+
+// Capability names an operation not every storage server offers.
+type Capability string
+
+const (
+	// CapabilityStat describes files and directories through GET /stat.
+	// Without it only files can be described, from a HEAD request.
+	CapabilityStat   Capability = "stat"
+	CapabilityList   Capability = "list"
+	CapabilityWrite  Capability = "write"
+	CapabilityDelete Capability = "delete"
+)
+
+// ParseCapabilities reads a comma separated list of capabilities, e.g.
+// "stat,list,write,delete".
+func ParseCapabilities(list string) ([]Capability, error) {
+	capabilities := []Capability{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+
+		capability := Capability(name)
+		switch capability {
+		case CapabilityStat, CapabilityList, CapabilityWrite, CapabilityDelete:
+			capabilities = append(capabilities, capability)
+		default:
+			return nil, fmt.Errorf("unknown storage capability %q", name)
+		}
+	}
+
+	return capabilities, nil
+}
+
+// FileInfo describes a file or directory in the storage.
+type FileInfo struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	IsDir   bool      `json:"isDir"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// Storage is where the photos, point clouds and pipeline outputs of the assets
+// live. Paths are the ones stored on the assets, e.g. /files/<uid>/<dir>.
+// HTTPStorage implements it for the storage server and LocalStorage for
+// development and tests.
+type Storage interface {
+	// Supports reports whether the storage offers the capability. The
+	// methods that need a missing one return ErrUnsupported.
+	Supports(capability Capability) bool
+	// Stat describes the file or directory at the path.
+	Stat(ctx context.Context, path string) (FileInfo, error)
+	// List describes the entries of the directory, sorted by path.
+	List(ctx context.Context, dir string) ([]FileInfo, error)
+	// Thumbnail returns the url of an image that represents the photos in
+	// the directory.
+	Thumbnail(ctx context.Context, dir string) (string, error)
+	// Peek returns the first n bytes of the file, or all of it when it is
+	// shorter.
+	Peek(ctx context.Context, path string, n int) ([]byte, error)
+	// Write stores data in the file at the path from offset on, creating the
+	// file and its directory when needed, and returns the bytes written. When
+	// it fails part way, the bytes it returns are known to be stored, so a
+	// retry can continue after them.
+	Write(ctx context.Context, path string, offset int64, data io.Reader) (int64, error)
+	// Delete removes the file or directory at the path. Deleting a path that
+	// does not exist is not an error.
+	Delete(ctx context.Context, path string) error
+	// SignedURL returns a url that grants read access to the path until it
+	// expires.
+	SignedURL(ctx context.Context, path string, expires time.Duration) (string, error)
+}
+
+var (
+	_ Storage = (*HTTPStorage)(nil)
+	_ Storage = (*LocalStorage)(nil)
+)
+
+// cleanPath turns the path into an absolute, slash separated path that cannot
+// climb out of the storage root.
+func cleanPath(p string) string {
+	return path.Clean("/" + strings.TrimSpace(p))
+}
+
+// signURL appends the expiry and the HMAC-SHA256 of the path and expiry keyed
+// with key, which the storage server checks before serving the file.
+func signURL(baseUrl string, key string, p string, expires time.Time) (string, error) {
+	if len(key) == 0 {
+		return "", fmt.Errorf("storage signing key is not configured")
+	}
+
+	p = cleanPath(p)
+	expiresAt := strconv.FormatInt(expires.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(p + "\n" + expiresAt))
+
+	query := url.Values{}
+	query.Set("expires", expiresAt)
+	query.Set("signature", hex.EncodeToString(mac.Sum(nil)))
+
+	return strings.TrimSuffix(baseUrl, "/") + p + "?" + query.Encode(), nil
+}
@@ -11,10 +11,27 @@ type Config struct {
 	DBSource            string        `mapstructure:"DB_SOURCE"`
 	ServerAddress       string        `mapstructure:"BACKEND_SERVER_ADDRESS"`
 	StorageUrl          string        `mapstructure:"STORAGE_SERVER_URL"`
+	StorageBackend      string        `mapstructure:"STORAGE_BACKEND"`
+	StorageLocalDir     string        `mapstructure:"STORAGE_LOCAL_DIR"`
+	StorageSigningKey   string        `mapstructure:"STORAGE_SIGNING_KEY"`
+	StorageCapabilities string        `mapstructure:"STORAGE_SERVER_CAPABILITIES"`
 	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RabbitSource        string        `mapstructure:"RABBIT_SOURCE"`
+	RabbitManagementUrl string        `mapstructure:"RABBIT_MANAGEMENT_URL"`
 	BackendSwaggerHost  string        `mapstructure:"BACKEND_SWAGGER_HOST"`
+	FrontendOrigins     string        `mapstructure:"FRONTEND_ORIGINS"`
+	WorkerTokens        string        `mapstructure:"WORKER_TOKENS"`
+	WatchdogInterval    time.Duration `mapstructure:"WATCHDOG_INTERVAL"`
+	StageTimeouts       string        `mapstructure:"STAGE_TIMEOUTS"`
+	StageMaxAttempts    int           `mapstructure:"STAGE_MAX_ATTEMPTS"`
+	MaxJobsPerUser      int           `mapstructure:"MAX_JOBS_PER_USER"`
+	ProcessPriority     int           `mapstructure:"PROCESS_PRIORITY"`
+	PipelinesFile       string        `mapstructure:"PIPELINES_FILE"`
+	WorkerStaleAfter    time.Duration `mapstructure:"WORKER_STALE_AFTER"`
+	UploadMinImages     int           `mapstructure:"UPLOAD_MIN_IMAGES"`
+	UploadMaxImages     int           `mapstructure:"UPLOAD_MAX_IMAGES"`
+	UploadMaxBytes      int64         `mapstructure:"UPLOAD_MAX_BYTES"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseOrigins parses a comma separated list of origins, e.g.
+// "https://segment3d.app,http://localhost:3000", into a set of normalized
+// "scheme://host[:port]" origins.
+func ParseOrigins(raw string) (map[string]bool, error) {
+	origins := make(map[string]bool)
+
+	for _, origin := range strings.Split(raw, ",") {
+		origin = strings.TrimSpace(origin)
+		if len(origin) == 0 {
+			continue
+		}
+
+		normalized, ok := NormalizeOrigin(origin)
+		if !ok {
+			return nil, fmt.Errorf("invalid origin %q: must be in the form scheme://host[:port]", origin)
+		}
+
+		origins[normalized] = true
+	}
+
+	return origins, nil
+}
+
+// NormalizeOrigin lower-cases the scheme and host of the origin. It reports
+// false if the origin is not in the form scheme://host[:port].
+func NormalizeOrigin(origin string) (string, bool) {
+	u, err := url.Parse(origin)
+	if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 || len(strings.Trim(u.Path, "/")) > 0 || u.User != nil || len(u.RawQuery) > 0 {
+		return "", false
+	}
+
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host), true
+}
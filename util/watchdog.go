@@ -0,0 +1,34 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseStageTimeouts parses a comma separated list of "stage:duration" pairs,
+// e.g. "colmap:6h,splat:4h", into a map of stage to timeout.
+func ParseStageTimeouts(raw string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+
+		stage, value, found := strings.Cut(pair, ":")
+		if !found || len(stage) == 0 {
+			return nil, fmt.Errorf("invalid stage timeout %q: must be in the form stage:duration", pair)
+		}
+
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid stage timeout %q: %q is not a positive duration", pair, value)
+		}
+
+		timeouts[stage] = timeout
+	}
+
+	return timeouts, nil
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseWorkerTokens parses a comma separated list of "workerId:token" pairs
+// into a map of worker id to token. Every worker needs a token of its own,
+// otherwise a request could not be told apart from one of another worker.
+func ParseWorkerTokens(raw string) (map[string]string, error) {
+	credentials := make(map[string]string)
+	owners := make(map[string]string)
+
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+
+		workerID, token, found := strings.Cut(pair, ":")
+		if !found || len(workerID) == 0 || len(token) == 0 {
+			return nil, fmt.Errorf("invalid worker token %q: must be in the form workerId:token", pair)
+		}
+
+		if _, exists := credentials[workerID]; exists {
+			return nil, fmt.Errorf("worker %s is configured more than once", workerID)
+		}
+
+		if owner, exists := owners[token]; exists {
+			return nil, fmt.Errorf("workers %s and %s share a token", owner, workerID)
+		}
+
+		credentials[workerID] = token
+		owners[token] = workerID
+	}
+
+	return credentials, nil
+}
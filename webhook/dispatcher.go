@@ -0,0 +1,191 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/segment3d-app/segment3d-be/db/sqlc"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of "<timestamp>.<body>" keyed
+	// with the secret of the webhook, as "sha256=<hex>".
+	SignatureHeader = "X-Segment3d-Signature"
+	// TimestampHeader carries the unix time the request was sent at. It is
+	// signed along with the body, so receivers can reject replayed requests
+	// whose timestamp is too old.
+	TimestampHeader = "X-Segment3d-Timestamp"
+	EventHeader     = "X-Segment3d-Event"
+	DeliveryHeader  = "X-Segment3d-Delivery"
+
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+
+	pollInterval = 5 * time.Second
+	batchSize    = 20
+	// leaseDuration keeps a claimed delivery from being sent twice at once.
+	leaseDuration  = time.Minute
+	requestTimeout = 10 * time.Second
+	dialTimeout    = 5 * time.Second
+	// a delivery is given up after maxAttempts, which with the backoff below
+	// spans roughly a day
+	maxAttempts = 12
+	minBackoff  = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+)
+
+// Sign returns the signature sent in SignatureHeader of the body sent at the
+// timestamp in TimestampHeader.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher posts the pending webhook deliveries to their endpoints and
+// retries failed ones with exponential backoff.
+type Dispatcher struct {
+	store  db.Store
+	client *http.Client
+}
+
+func NewDispatcher(store db.Store) *Dispatcher {
+	// the proxy is left unset, since the control would only see the address
+	// of the proxy rather than of the endpoint
+	dialer := &net.Dialer{Timeout: dialTimeout, Control: dialControl}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: dialTimeout,
+	}
+
+	return &Dispatcher{
+		store:  store,
+		client: &http.Client{Timeout: requestTimeout, Transport: transport},
+	}
+}
+
+// Run sends pending deliveries until the context is cancelled.
+func (dispatcher *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := dispatcher.deliverPending(ctx)
+			if err != nil {
+				log.Printf("can't deliver webhooks: %v", err)
+			}
+			if err != nil || n < batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (dispatcher *Dispatcher) deliverPending(ctx context.Context) (int, error) {
+	deliveries, err := dispatcher.store.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		Limit:         batchSize,
+		NextAttemptAt: time.Now().Add(leaseDuration),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		responseStatus, err := dispatcher.deliver(ctx, delivery)
+		status := sql.NullInt32{Int32: int32(responseStatus), Valid: responseStatus != 0}
+		if err == nil {
+			err = dispatcher.store.MarkWebhookDeliveryDelivered(ctx, db.MarkWebhookDeliveryDeliveredParams{
+				ID:             delivery.ID,
+				ResponseStatus: status,
+			})
+			if err != nil {
+				return len(deliveries), err
+			}
+			continue
+		}
+
+		arg := db.MarkWebhookDeliveryFailedParams{
+			ID:             delivery.ID,
+			Status:         StatusPending,
+			ResponseStatus: status,
+			LastError:      sql.NullString{String: err.Error(), Valid: true},
+			NextAttemptAt:  time.Now().Add(backoff(delivery.Attempts)),
+		}
+		if delivery.Attempts >= maxAttempts {
+			arg.Status = StatusFailed
+		}
+
+		err = dispatcher.store.MarkWebhookDeliveryFailed(ctx, arg)
+		if err != nil {
+			return len(deliveries), err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// deliver posts the delivery and returns the response status. Any status
+// outside 2xx counts as a failure.
+func (dispatcher *Dispatcher) deliver(ctx context.Context, delivery db.WebhookDeliveries) (int, error) {
+	hook, err := dispatcher.store.GetWebhookById(ctx, delivery.WebhooksId)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, timestamp, delivery.Payload))
+
+	resp, err := dispatcher.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func backoff(attempts int32) time.Duration {
+	delay := minBackoff
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrForbiddenTarget is returned for webhook urls that point into the network
+// of the backend rather than at a public endpoint.
+var ErrForbiddenTarget = errors.New("webhook target is not a public address")
+
+// reservedPrefixes are ranges that are not public beyond those netip already
+// tells apart, such as the shared address space of carrier-grade NAT.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// isPublic reports whether the address may be posted to.
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckURL makes sure the webhook url is http or https and that its host only
+// resolves to public addresses, so users cannot make the backend post to
+// loopback, private or link-local services. The dispatcher checks the address
+// it dials again, since the host may resolve differently later.
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook url must be http or https, not %q", u.Scheme)
+	}
+
+	host := u.Hostname()
+	if len(host) == 0 {
+		return fmt.Errorf("webhook url has no host")
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("can't resolve webhook host %s: %w", host, err)
+	}
+
+	for _, addr := range addrs {
+		if !isPublic(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenTarget, host, addr.Unmap())
+		}
+	}
+
+	return nil
+}
+
+// dialControl refuses connections to addresses that are not public. It runs
+// after the host was resolved, so it also covers redirects and hosts whose
+// records changed since the webhook was registered.
+func dialControl(network string, address string, c syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !isPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, addrPort.Addr())
+	}
+
+	return nil
+}